@@ -5,9 +5,12 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/bruteforce"
 	"github.com/Z-egorov/Go-Brute-Scanner/pkg/output"
 	"github.com/Z-egorov/Go-Brute-Scanner/pkg/scanner"
 	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
@@ -20,17 +23,82 @@ func main() {
 		workers    = flag.Int("workers", 10, "Number of concurrent workers")
 		timeout    = flag.Int("timeout", 30, "Timeout in seconds")
 		depth      = flag.Int("depth", 2, "Crawl depth")
+		maxPages   = flag.Int("max-pages", 1000, "Max pages fetched by crawler (0 = unlimited)")
+		seeds      = flag.Bool("seeds", false, "Seed crawl with robots.txt paths and sitemap URLs")
+		polite     = flag.Bool("honor-robots", false, "Do not crawl pages disallowed by robots.txt")
+		openAPI    = flag.Bool("openapi", false, "Probe well-known OpenAPI/Swagger locations while crawling")
+		specFile   = flag.String("spec", "", "Import API operations from local OpenAPI/Swagger file (JSON or YAML)")
+		scopeHosts = flag.String("scope-hosts", "", "Extra in-scope hosts, e.g. api.example.com,*.example.com")
+		prefixes   = flag.String("scope-paths", "", "Only request paths under prefixes, e.g. /api,/admin")
+		schemes    = flag.String("scope-schemes", "", "Allowed schemes (default http,https)")
+		ports      = flag.String("scope-ports", "", "Allowed ports (default base port)")
 		delay      = flag.Int("delay", 100, "Delay between requests in ms")
+		rate       = flag.Int("rate", 10, "Max requests per second for all workers (0 = unlimited)")
+		burst      = flag.Int("burst", 1, "Rate limiter burst size")
+		retries    = flag.Int("retries", 3, "Max attempts per request on network errors")
 		methods    = flag.String("methods", "GET,POST,PUT,DELETE", "HTTP methods to test")
 		outputFile = flag.String("output", "results.json", "Output file")
 		format     = flag.String("format", "json", "Output format (json, md, txt)")
 		discover   = flag.Bool("discover", true, "Enable auto-discovery")
 		brute      = flag.Bool("brute", true, "Enable brute force")
 		quiet      = flag.Bool("quiet", false, "Quiet mode (only results)")
+		mc         = flag.String("mc", "", "Match status codes, e.g. 200,301-399 or all")
+		ms         = flag.String("ms", "", "Match response size, e.g. 100-200,>1000")
+		mw         = flag.String("mw", "", "Match word count")
+		ml         = flag.String("ml", "", "Match line count")
+		mr         = flag.String("mr", "", "Match body regex")
+		mh         = flag.String("mh", "", "Match header regex (\"Name: value\")")
+		mt         = flag.String("mt", "", "Match response time in ms, e.g. >500")
+		fc         = flag.String("fc", "", "Filter status codes")
+		fs         = flag.String("fs", "", "Filter response size")
+		fw         = flag.String("fw", "", "Filter word count")
+		fl         = flag.String("fl", "", "Filter line count")
+		fr         = flag.String("fr", "", "Filter body regex")
+		fh         = flag.String("fh", "", "Filter header regex")
+		ft         = flag.String("ft", "", "Filter response time in ms")
+		mmode      = flag.String("mmode", "or", "Matcher mode (or, and)")
+		fmode      = flag.String("fmode", "or", "Filter mode (or, and)")
+		recursion  = flag.Int("recursion", 0, "Recursion depth into found directories (0 = off)")
+		maxTasks   = flag.Int("max-tasks", bruteforce.DefaultMaxTasks, "Max brute force requests added by recursion and git tracked paths (0 = unlimited)")
+		extensions = flag.String("extensions", "", "Extensions to append to words, e.g. php,json,bak")
+		suffixes   = flag.String("suffixes", "", "Suffixes to append to words and extensions, e.g. ~,.old,.swp")
+		calibrate  = flag.Bool("calibrate", true, "Detect wildcard and soft-404 responses before brute force")
+		checkpoint = flag.String("checkpoint", "", "Save brute force state to file so interrupted scan can be resumed")
+		cpInterval = flag.Int("checkpoint-interval", 30, "Seconds between checkpoints")
+		resume     = flag.String("resume", "", "Resume brute force scan from checkpoint file, use flags of interrupted scan")
+		stream     = flag.Bool("stream", false, "Print brute force hits as they are found")
 		wordlist   = flag.String("wordlist", "", "Custom wordlist file (one per line)")
 		proxies    = flag.String("proxies", "", "Proxy list file (one per line)")
+		cookies    = flag.String("cookies", "", "Netscape cookie file to load")
+		saveCookie = flag.String("save-cookies", "", "Save session cookies to Netscape cookie file")
+		fuzzURL    = flag.String("fuzz-url", "", "Request template URL with FUZZ keyword, e.g. /api?action=FUZZ")
+		fuzzMethod = flag.String("fuzz-method", "GET", "Request template method")
+		fuzzData   = flag.String("fuzz-data", "", "Request template body, may contain FUZZ")
+		fuzzMode   = flag.String("mode", "clusterbomb", "Attack mode for several fuzz wordlists (clusterbomb, pitchfork, sniper)")
+		smartVerbs = flag.Bool("method-discovery", false, "Probe each path with one method, try others only where OPTIONS/Allow/405 suggest")
+		extraVerbs = flag.String("extra-methods", "", "Extra methods for method discovery, e.g. PATCH,HEAD,TRACE,PROPFIND")
+		override   = flag.Bool("method-override", false, "Probe confirmed paths with X-HTTP-Method-Override and _method")
+		bypass     = flag.Bool("bypass", false, "Re-test 401/403 hits with path and header bypass tricks")
+		vhosts     = flag.String("vhosts", "", "Virtual host names file, fuzzes Host header and SNI against base URL")
+		artifacts  = flag.Bool("artifacts", false, "Probe backups, swap files, archives, .git/.svn and source maps of found paths")
+		gitDump    = flag.Bool("git", false, "Reconstruct exposed .git and scan tracked paths first (enables -artifacts)")
+		gitDir     = flag.String("git-dir", "", "Write blobs of exposed .git to directory")
+		params     = flag.Bool("params", false, "Discover hidden query/body parameters of found endpoints")
+		paramList  = flag.String("params-wordlist", "", "Parameter names file (one per line)")
+		paramBatch = flag.Int("params-batch", bruteforce.DefaultParamBatch, "Parameter names sent per request")
+		auth       = flag.String("auth", "", "Auth: basic:user:pass, bearer:token, apikey:key, header:Name:value")
 	)
 
+	var fuzzHeaders headerFlags
+	flag.Var(&fuzzHeaders, "fuzz-header", "Request template header \"Name: value\", may contain FUZZ (repeatable)")
+	var fuzzWordlists, fuzzDefaults headerFlags
+	flag.Var(&fuzzWordlists, "fuzz-wordlist", "Wordlist for template keyword \"KEYWORD:file\" (repeatable)")
+	var include, exclude, never listFlags
+	flag.Var(&include, "include", "Only request URLs matching regex (repeatable)")
+	flag.Var(&exclude, "exclude", "Never request URLs matching regex (repeatable)")
+	flag.Var(&never, "never", "Never request URLs matching regex instead of default logout patterns (repeatable)")
+	flag.Var(&fuzzDefaults, "fuzz-default", "Sniper mode value of keyword not fuzzed \"KEYWORD:value\" (repeatable)")
+
 	flag.Parse()
 
 	if *url == "" {
@@ -48,7 +116,90 @@ func main() {
 		scanner.WithTimeout(time.Duration(*timeout) * time.Second),
 		scanner.WithWorkers(*workers),
 		scanner.WithScanDepth(*depth),
+		scanner.WithMaxPages(*maxPages),
+		scanner.WithSeeds(*seeds),
+		scanner.WithHonorRobots(*polite),
+		scanner.WithOpenAPI(*openAPI),
 		scanner.WithUserAgent("GoBruteScanner-CLI/1.0"),
+		scanner.WithRateLimit(*rate, *burst),
+		scanner.WithCalibration(*calibrate),
+		scanner.WithParamBatch(*paramBatch),
+		scanner.WithBypass(*bypass),
+		scanner.WithArtifacts(*artifacts),
+		scanner.WithRecursion(*recursion),
+		scanner.WithMaxTasks(*maxTasks),
+		scanner.WithExtensions(splitList(*extensions)...),
+		scanner.WithSuffixes(splitList(*suffixes)...),
+		scanner.WithRetryPolicy(types.RetryPolicy{
+			MaxAttempts: *retries,
+			RetryStatus: []int{502, 504},
+		}),
+		scanner.WithMatchConfig(types.MatchConfig{
+			MatchStatus:  *mc,
+			MatchSize:    *ms,
+			MatchWords:   *mw,
+			MatchLines:   *ml,
+			MatchRegex:   *mr,
+			MatchHeader:  *mh,
+			MatchTime:    *mt,
+			MatchMode:    *mmode,
+			FilterStatus: *fc,
+			FilterSize:   *fs,
+			FilterWords:  *fw,
+			FilterLines:  *fl,
+			FilterRegex:  *fr,
+			FilterHeader: *fh,
+			FilterTime:   *ft,
+			FilterMode:   *fmode,
+		}),
+	}
+
+	scopeConfig := types.ScopeConfig{
+		Include:      include,
+		Exclude:      exclude,
+		Hosts:        splitList(*scopeHosts),
+		Schemes:      splitList(*schemes),
+		PathPrefixes: splitList(*prefixes),
+	}
+	if len(never) > 0 {
+		scopeConfig.Never = never
+	}
+	for _, port := range splitList(*ports) {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			fmt.Printf("❌ Invalid port %q\n", port)
+			os.Exit(1)
+		}
+		scopeConfig.Ports = append(scopeConfig.Ports, n)
+	}
+	opts = append(opts, scanner.WithScope(scopeConfig))
+
+	if *auth != "" {
+		authConfig, err := parseAuth(*auth)
+		if err != nil {
+			fmt.Printf("❌ Invalid auth: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, scanner.WithAuth(authConfig))
+		if !*quiet {
+			fmt.Printf("[*] Using %s auth\n", authConfig.Type)
+		}
+	}
+
+	if *smartVerbs {
+		opts = append(opts, scanner.WithMethodDiscovery(splitList(*extraVerbs)...))
+	}
+
+	if *gitDump || *gitDir != "" {
+		opts = append(opts, scanner.WithGitDump(*gitDir))
+	}
+
+	if *override {
+		opts = append(opts, scanner.WithMethodOverride())
+	}
+
+	if *cookies != "" {
+		opts = append(opts, scanner.WithCookieFile(*cookies))
 	}
 
 	if *proxies != "" {
@@ -62,6 +213,14 @@ func main() {
 		}
 	}
 
+	checkpointFile := *checkpoint
+	if checkpointFile == "" {
+		checkpointFile = *resume
+	}
+	if checkpointFile != "" {
+		opts = append(opts, scanner.WithCheckpoint(checkpointFile, time.Duration(*cpInterval)*time.Second))
+	}
+
 	s, err := scanner.New(*url, opts...)
 	if err != nil {
 		fmt.Printf("❌ Failed to create scanner: %v\n", err)
@@ -69,10 +228,30 @@ func main() {
 	}
 
 	ctx := context.Background()
+	if checkpointFile != "" {
+		// Ctrl-C stops scan and leaves checkpoint to resume from
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+	}
 
 	var allResults []types.ScanResult
 
-	if *discover {
+	if *specFile != "" {
+		operations, err := s.ImportSpec(*specFile)
+		if err != nil {
+			fmt.Printf("❌ Failed to import spec: %v\n", err)
+			os.Exit(1)
+		}
+		if !*quiet {
+			fmt.Printf("\n📘 Imported %d API operations from %s\n", len(operations), *specFile)
+			for _, op := range operations {
+				fmt.Printf("   %-7s %s\n", op.Method, op.URL)
+			}
+		}
+	}
+
+	if *discover && *resume == "" {
 		if !*quiet {
 			fmt.Println("\n[1/2] 🔍 Auto-discovery phase")
 		}
@@ -83,7 +262,19 @@ func main() {
 		}
 
 		if !*quiet {
-			fmt.Printf("   Discovered %d endpoints\n", len(endpoints))
+			outOfScope, operations := 0, 0
+			for _, endpoint := range endpoints {
+				if endpoint.Metadata["scope"] == types.OutOfScope {
+					outOfScope++
+				}
+				if endpoint.Source == "openapi" && endpoint.Metadata["spec_document"] == nil {
+					operations++
+				}
+			}
+			fmt.Printf("   Discovered %d endpoints (%d out of scope)\n", len(endpoints), outOfScope)
+			if operations > 0 {
+				fmt.Printf("   Imported %d API operations from OpenAPI specs\n", operations)
+			}
 		}
 	}
 
@@ -113,24 +304,69 @@ func main() {
 
 		if !*quiet {
 			fmt.Printf("   Testing with methods: %s\n", strings.Join(methodList, ", "))
-			fmt.Printf("   Workers: %d, Delay: %dms\n", *workers, *delay)
+			fmt.Printf("   Workers: %d, Delay: %dms, Rate: %d req/s\n", *workers, *delay, *rate)
 			fmt.Println("   Scanning...")
 		}
 
-		results, err := s.ScanWithWordlist(
-			ctx,
-			wordlistItems,
-			methodList,
-			*workers,
-			time.Duration(*delay)*time.Millisecond,
-		)
+		var results []types.ScanResult
+		var err error
+		if *resume != "" {
+			if !*quiet {
+				fmt.Printf("   Resuming from %s\n", *resume)
+			}
+			results, err = s.ResumeScan(ctx, *resume, *workers, time.Duration(*delay)*time.Millisecond)
+		} else if *fuzzURL != "" {
+			tmpl := types.RequestTemplate{
+				Method:  strings.ToUpper(*fuzzMethod),
+				URL:     *fuzzURL,
+				Headers: fuzzHeaders.toMap(),
+				Body:    *fuzzData,
+			}
+			if len(fuzzWordlists) > 0 {
+				payloads := make(map[string][]string, len(fuzzWordlists))
+				for keyword, file := range fuzzWordlists.toMap() {
+					payloads[keyword] = loadLinesFromFile(file)
+				}
+				tmpl.Defaults = fuzzDefaults.toMap()
+				results, err = s.ScanAttack(ctx, tmpl, payloads, bruteforce.AttackMode(*fuzzMode), *workers, time.Duration(*delay)*time.Millisecond)
+			} else {
+				results, err = s.ScanTemplate(ctx, tmpl, wordlistItems, *workers, time.Duration(*delay)*time.Millisecond)
+			}
+		} else if *stream {
+			err = s.StreamWithWordlist(ctx, wordlistItems, methodList, *workers, time.Duration(*delay)*time.Millisecond,
+				func(result types.ScanResult) error {
+					result.Body = ""
+					results = append(results, result)
+					switch {
+					case result.Finding != "":
+						fmt.Printf("   » %s\n", describeFinding(result))
+					case !result.Wildcard && result.Error == "" && result.StatusCode < 400:
+						fmt.Printf("   » [%d] %s %s (%d bytes)\n", result.StatusCode, result.Method, result.URL, result.Size)
+					}
+					return nil
+				})
+		} else {
+			results, err = s.ScanWithWordlist(
+				ctx,
+				wordlistItems,
+				methodList,
+				*workers,
+				time.Duration(*delay)*time.Millisecond,
+			)
+		}
 		if err != nil {
 			fmt.Printf("❌ Scan failed: %v\n", err)
-			os.Exit(1)
+			if results == nil {
+				os.Exit(1)
+			}
 		}
 
 		allResults = results
 
+		if ctx.Err() != nil && checkpointFile != "" {
+			fmt.Printf("⏸️ Scan interrupted, continue with -resume %s\n", checkpointFile)
+		}
+
 		if !*quiet {
 			fmt.Printf("   Completed %d requests\n", len(results))
 		}
@@ -140,11 +376,37 @@ func main() {
 		fmt.Println("\n📊 Results Analysis")
 	}
 
+	if *vhosts != "" {
+		names := loadLinesFromFile(*vhosts)
+		if !*quiet {
+			fmt.Printf("\n🏷️ Virtual host discovery (%d names)\n", len(names))
+		}
+
+		results, err := s.ScanVhosts(ctx, names, *workers, time.Duration(*delay)*time.Millisecond)
+		if err != nil {
+			fmt.Printf("⚠️ Vhost scan error: %v\n", err)
+		}
+		allResults = append(allResults, results...)
+	}
+
+	if *params {
+		paramEndpoints(ctx, s, allResults, *paramList, *workers, *quiet)
+	}
+
 	statusCounts := make(map[int]int)
-	var successful []types.ScanResult
+	var successful, findings []types.ScanResult
+	wildcards := 0
 
 	for _, result := range allResults {
+		if result.Finding != "" {
+			findings = append(findings, result)
+			continue
+		}
 		statusCounts[result.StatusCode]++
+		if result.Wildcard {
+			wildcards++
+			continue
+		}
 		if result.StatusCode >= 200 && result.StatusCode < 300 {
 			successful = append(successful, result)
 		}
@@ -167,6 +429,10 @@ func main() {
 			fmt.Printf("   %s %d: %d requests\n", emoji, code, count)
 		}
 
+		if wildcards > 0 {
+			fmt.Printf("\n🃏 Wildcard responses suppressed: %d\n", wildcards)
+		}
+
 		fmt.Printf("\n✅ Successful endpoints (%d):\n", len(successful))
 		for i, result := range successful {
 			if i >= 20 {
@@ -180,10 +446,20 @@ func main() {
 			fmt.Printf("   • [%d] %s %s (%d bytes)\n",
 				result.StatusCode, result.Method, result.URL, result.Size)
 		}
+
+		if len(findings) > 0 {
+			fmt.Printf("\n🔎 Findings (%d):\n", len(findings))
+			for _, result := range findings {
+				fmt.Printf("   • %s\n", describeFinding(result))
+			}
+		}
 	} else {
 		for _, result := range successful {
 			fmt.Printf("%s %s [%d]\n", result.Method, result.URL, result.StatusCode)
 		}
+		for _, result := range findings {
+			fmt.Println(describeFinding(result))
+		}
 	}
 
 	if *outputFile != "" {
@@ -193,12 +469,28 @@ func main() {
 		}
 	}
 
+	if *saveCookie != "" {
+		if err := s.CookieJar().SaveFile(*saveCookie); err != nil {
+			fmt.Printf("Warning: Failed to save cookies: %v\n", err)
+		} else if !*quiet {
+			fmt.Printf("🍪 Cookies saved to %s\n", *saveCookie)
+		}
+	}
+
 	if !*quiet {
 		stats := s.GetStats()
 		fmt.Println("\n📈 Final Statistics:")
 		fmt.Printf("   • Total requests: %d\n", stats.TotalRequests)
 		fmt.Printf("   • Successful (2xx): %d\n", stats.Successful)
 		fmt.Printf("   • Failed (4xx/5xx): %d\n", stats.Failed)
+		fmt.Printf("   • Throttled (429/503): %d, retries: %d, slowdowns: %d\n",
+			stats.Throttled, stats.Retries, stats.Slowdowns)
+		if stats.Truncated > 0 {
+			fmt.Printf("   • ⚠️ Dropped over -max-tasks: %d\n", stats.Truncated)
+		}
+		if stats.OutOfScope > 0 {
+			fmt.Printf("   • Skipped out of scope (see -never, -exclude): %d\n", stats.OutOfScope)
+		}
 		fmt.Printf("   • Total time: %v\n", stats.Duration)
 		fmt.Printf("   • Requests/sec: %.1f\n",
 			float64(stats.TotalRequests)/stats.Duration.Seconds())
@@ -207,6 +499,62 @@ func main() {
 	}
 }
 
+func paramEndpoints(ctx context.Context, s scanner.Scanner, results []types.ScanResult, wordlist string, workers int, quiet bool) {
+	var endpoints []types.Endpoint
+	seen := make(map[string]bool)
+	for _, result := range results {
+		key := result.Method + " " + result.URL
+		if result.Wildcard || result.StatusCode < 200 || result.StatusCode >= 300 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		endpoints = append(endpoints, types.Endpoint{URL: result.URL, Method: result.Method, Source: result.FoundVia})
+	}
+
+	var names []string
+	if wordlist != "" {
+		names = loadLinesFromFile(wordlist)
+	}
+
+	if !quiet {
+		fmt.Printf("\n🧪 Parameter discovery on %d endpoints\n", len(endpoints))
+	}
+
+	found, err := s.FindParams(ctx, endpoints, names, workers)
+	if err != nil {
+		fmt.Printf("⚠️ Parameter discovery error: %v\n", err)
+	}
+
+	for _, endpoint := range found {
+		params, _ := endpoint.Metadata["params"].([]types.Parameter)
+		for _, p := range params {
+			fmt.Printf("   • %s %s %s=%s (%s)\n", endpoint.Method, endpoint.URL, p.Location, p.Name, strings.Join(p.Reasons, ", "))
+		}
+	}
+}
+
+func describeFinding(result types.ScanResult) string {
+	switch result.Finding {
+	case types.FindingMethodOverride:
+		return fmt.Sprintf("[%s] %s %s acts as %s via %s [%d]",
+			result.Finding, result.Method, result.URL, result.EffectiveMethod, result.Override, result.StatusCode)
+	case types.FindingArtifact:
+		line := fmt.Sprintf("[%s] %s %s derived from %s [%d] (%d bytes)",
+			result.Finding, result.Artifact, result.URL, result.Parent, result.StatusCode, result.Size)
+		if len(result.Files) > 0 {
+			line += fmt.Sprintf(", %d tracked files", len(result.Files))
+		}
+		return line
+	case types.FindingVhost:
+		return fmt.Sprintf("[%s] %s on %s [%d] (%d bytes)",
+			result.Finding, result.Host, result.URL, result.StatusCode, result.Size)
+	case types.FindingAccessBypass:
+		return fmt.Sprintf("[%s] %s blocked, %s works [%d]:\n%s",
+			result.Finding, result.Parent, result.Bypass, result.StatusCode, result.Request)
+	}
+	return fmt.Sprintf("[%s] %s %s [%d]", result.Finding, result.Method, result.URL, result.StatusCode)
+}
+
 func printBanner() {
 	fmt.Println(`
 ╔══════════════════════════════════════════╗
@@ -233,6 +581,67 @@ func loadLinesFromFile(filename string) []string {
 	return result
 }
 
+type listFlags []string
+
+func (l *listFlags) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *listFlags) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(value string) error {
+	if !strings.Contains(value, ":") {
+		return fmt.Errorf("header must be \"Name: value\"")
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+func (h headerFlags) toMap() map[string]string {
+	headers := make(map[string]string, len(h))
+	for _, header := range h {
+		parts := strings.SplitN(header, ":", 2)
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return headers
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func parseAuth(value string) (types.AuthConfig, error) {
+	parts := strings.SplitN(value, ":", 3)
+	authType := strings.ToLower(parts[0])
+
+	switch {
+	case authType == types.AuthBasic && len(parts) == 3:
+		return types.AuthConfig{Type: authType, Username: parts[1], Password: parts[2]}, nil
+	case authType == types.AuthBearer && len(parts) >= 2:
+		return types.AuthConfig{Type: authType, Token: strings.Join(parts[1:], ":")}, nil
+	case authType == types.AuthAPIKey && len(parts) >= 2:
+		return types.AuthConfig{Type: authType, APIKey: strings.Join(parts[1:], ":")}, nil
+	case authType == types.AuthHeader && len(parts) == 3:
+		return types.AuthConfig{Type: authType, Header: parts[1], Token: parts[2]}, nil
+	}
+	return types.AuthConfig{}, fmt.Errorf("unsupported auth format %q", authType)
+}
+
 func exportResults(results []types.ScanResult, filename, format string) {
 	var formatter output.Formatter
 	var data string
@@ -5,6 +5,7 @@ go 1.24.2
 require (
 	github.com/PuerkitoBio/goquery v1.11.0
 	golang.org/x/net v0.47.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require github.com/andybalholm/cascadia v1.3.3 // indirect
@@ -0,0 +1,272 @@
+package bruteforce
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/gitdump"
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// Artifact kinds
+const (
+	ArtifactBackup    = "backup"
+	ArtifactSwap      = "swap"
+	ArtifactArchive   = "archive"
+	ArtifactGit       = "git"
+	ArtifactSVN       = "svn"
+	ArtifactDSStore   = "ds_store"
+	ArtifactSourceMap = "source-map"
+)
+
+// WithArtifacts probes backup copies, swap files, archives, VCS metadata and source maps derived from found paths
+func WithArtifacts(enabled bool) Option {
+	return func(s *scannerImpl) {
+		s.artifacts = enabled
+	}
+}
+
+// artifact candidate derived from found path
+type artifact struct {
+	word string
+	kind string
+	// suffix appended to parent, candidates without signature are checked against random word with same suffix
+	suffix string
+	// signature checks body, nil means baseline check
+	signature func(body string) bool
+}
+
+// backupSuffixes copies editors and admins leave next to files
+var backupSuffixes = []string{".bak", ".old", ".orig", ".save", ".tmp", ".copy", ".1", "~"}
+
+// archiveSuffixes archives of directory or file
+var archiveSuffixes = []string{".zip", ".tar.gz", ".tgz", ".tar", ".rar", ".7z", ".gz"}
+
+var gitHead = regexp.MustCompile(`^(ref: refs/|[0-9a-f]{40}\s*$)`)
+
+// derivedArtifacts returns candidates for found word, dir artifacts are returned by dirArtifacts
+func derivedArtifacts(word string) []artifact {
+	word = strings.TrimSuffix(word, "/")
+	dir, name := path.Split(word)
+	ext := path.Ext(name)
+
+	var candidates []artifact
+	for _, suffix := range backupSuffixes {
+		candidates = append(candidates, artifact{word: word + suffix, kind: ArtifactBackup, suffix: suffix})
+	}
+	if ext != "" {
+		stem := strings.TrimSuffix(name, ext)
+		candidates = append(candidates,
+			artifact{word: dir + stem + ".bak", kind: ArtifactBackup, suffix: ".bak"},
+			artifact{word: dir + stem + ".old", kind: ArtifactBackup, suffix: ".old"},
+		)
+	}
+
+	candidates = append(candidates,
+		artifact{word: dir + "." + name + ".swp", kind: ArtifactSwap, signature: vimSwap},
+		artifact{word: dir + "." + name + ".swo", kind: ArtifactSwap, signature: vimSwap},
+		artifact{word: word + ".swp", kind: ArtifactSwap, signature: vimSwap},
+	)
+
+	for _, suffix := range archiveSuffixes {
+		candidates = append(candidates, artifact{word: word + suffix, kind: ArtifactArchive, signature: archive})
+	}
+
+	switch ext {
+	case ".js", ".css", ".mjs":
+		candidates = append(candidates, artifact{word: word + ".map", kind: ArtifactSourceMap, signature: sourceMap})
+	}
+
+	return candidates
+}
+
+// dirArtifacts returns VCS and Finder metadata candidates of dir
+func dirArtifacts(dir string) []artifact {
+	return []artifact{
+		{word: dir + ".git/HEAD", kind: ArtifactGit, signature: func(body string) bool { return gitHead.MatchString(body) }},
+		{word: dir + ".svn/entries", kind: ArtifactSVN, signature: svnEntries},
+		{word: dir + ".svn/wc.db", kind: ArtifactSVN, signature: func(body string) bool { return strings.HasPrefix(body, "SQLite format 3") }},
+		{word: dir + ".DS_Store", kind: ArtifactDSStore, signature: func(body string) bool { return strings.HasPrefix(body, "\x00\x00\x00\x01Bud1") }},
+	}
+}
+
+// probeArtifacts requests artifacts derived from found result and stores hits
+func (s *scannerImpl) probeArtifacts(ctx context.Context, run *scanRun, t task, parent types.BruteResult) {
+	word := strings.TrimPrefix(t.word, "/")
+
+	candidates := derivedArtifacts(word)
+	dirs := []string{dirOf(word)}
+	if path.Ext(strings.TrimSuffix(word, "/")) == "" {
+		dirs = append(dirs, strings.TrimSuffix(word, "/")+"/")
+	}
+	for _, dir := range dirs {
+		if run.once("artifacts dir " + dir) {
+			candidates = append(candidates, dirArtifacts(dir)...)
+		}
+	}
+
+	for _, a := range candidates {
+		if ctx.Err() != nil {
+			return
+		}
+		artifactURL := joinURL(run.baseURL, a.word)
+		if !run.once("artifact "+a.word) || !s.scope.Allows(artifactURL) {
+			continue
+		}
+
+		result := s.testEndpoint(ctx, artifactURL, http.MethodGet)
+		if result.Error != "" || result.StatusCode != http.StatusOK || result.Size == 0 {
+			continue
+		}
+
+		if a.signature != nil {
+			if !a.signature(result.Body) {
+				continue
+			}
+		} else if s.artifactWildcard(ctx, run, a, result) {
+			continue
+		}
+
+		result.Finding = types.FindingArtifact
+		result.Artifact = a.kind
+		result.Parent = parent.URL
+		result.Depth = t.depth
+		result.Word = a.word
+		if dir := strings.TrimSuffix(a.word, ".git/HEAD"); a.kind == ArtifactGit && s.gitDump && (dir == "" || gitdump.LocalPath(dir)) {
+			// repository is dumped by its own task outside step, hit is stored once dump lists its files
+			if run.tasks.pushFirst(task{url: joinURL(run.baseURL, dir+".git/"), word: dir, depth: t.depth, hit: &result}) {
+				continue
+			}
+		}
+		s.store(run, result)
+	}
+}
+
+// artifactWildcard checks result against random word with same suffix in same directory
+func (s *scannerImpl) artifactWildcard(ctx context.Context, run *scanRun, a artifact, result types.BruteResult) bool {
+	dir := dirOf(a.word)
+
+	run.mu.Lock()
+	if run.artifactCal == nil {
+		run.artifactCal = newCalibration()
+	}
+	cal := run.artifactCal
+	run.mu.Unlock()
+
+	cal.mu.RLock()
+	_, ok := cal.baselines[baselineKey(http.MethodGet, dir, a.suffix)]
+	cal.mu.RUnlock()
+
+	if !ok {
+		b := baseline(http.MethodGet, dir, a.suffix, func(word string) types.BruteResult {
+			return s.testEndpoint(ctx, joinURL(run.baseURL, word), http.MethodGet)
+		})
+		if b == nil {
+			return true
+		}
+		cal.add(b)
+	}
+
+	return cal.isWildcard(result, []string{a.word}, dir, a.suffix)
+}
+
+// vimSwap checks vim swap file header
+func vimSwap(body string) bool {
+	return strings.HasPrefix(body, "b0VIM")
+}
+
+// svnEntries checks old svn entries file, format number or xml
+func svnEntries(body string) bool {
+	body = strings.TrimSpace(body)
+	return strings.HasPrefix(body, "<?xml") && strings.Contains(body, "wc-entries") ||
+		len(body) > 0 && body[0] >= '0' && body[0] <= '9' && strings.Contains(body, "\ndir\n")
+}
+
+// sourceMap checks source map JSON
+func sourceMap(body string) bool {
+	return strings.Contains(body, `"mappings"`) && strings.Contains(body, `"version"`)
+}
+
+// archive checks zip, gzip, rar, 7z and tar magic
+func archive(body string) bool {
+	data := []byte(body)
+	for _, magic := range [][]byte{{'P', 'K', 3, 4}, {0x1f, 0x8b}, []byte("Rar!"), {'7', 'z', 0xbc, 0xaf}} {
+		if bytes.HasPrefix(data, magic) {
+			return true
+		}
+	}
+	return len(data) > 262 && string(data[257:262]) == "ustar"
+}
+
+// WithGitDump reconstructs repositories behind found .git/HEAD and queues tracked paths ahead of wordlist,
+// non-empty outDir gets blobs written to it
+func WithGitDump(enabled bool, outDir string) Option {
+	return func(s *scannerImpl) {
+		s.gitDump = enabled
+		s.gitDumpDir = outDir
+	}
+}
+
+// dumpGit lists tracked files of repository found by dump task, stores its hit with them
+// and queues them as urgent tasks, download runs without step so pause and checkpoint do not wait for it
+func (s *scannerImpl) dumpGit(ctx context.Context, run *scanRun, t task) {
+	outDir := s.gitDumpDir
+	if outDir != "" && t.word != "" {
+		if !gitdump.LocalPath(t.word) {
+			outDir = ""
+		} else {
+			outDir = filepath.Join(outDir, filepath.FromSlash(t.word))
+		}
+	}
+
+	repo, _ := gitdump.New(s.client, run.concurrency).Dump(ctx, t.url, outDir)
+
+	run.step.RLock()
+	defer run.step.RUnlock()
+	defer run.tasks.done()
+
+	run.mu.Lock()
+	delete(run.dumping, t.word)
+	run.mu.Unlock()
+
+	if ctx.Err() != nil {
+		run.tasks.requeue(t)
+		return
+	}
+
+	hit := *t.hit
+	if repo == nil {
+		s.store(run, hit)
+		return
+	}
+
+	hit.Files = make([]string, len(repo.Files))
+	for i, f := range repo.Files {
+		hit.Files[i] = f.Path
+	}
+	s.store(run, hit)
+
+	for _, f := range repo.Files {
+		word := t.word + f.Path
+		if !run.once("git " + word) {
+			continue
+		}
+
+		next := task{
+			url:    joinURL(run.baseURL, word),
+			method: http.MethodGet,
+			word:   word,
+			base:   word,
+			depth:  t.depth,
+			parent: t.url,
+		}
+		if !run.tasks.pushUrgent(next) {
+			break
+		}
+	}
+}
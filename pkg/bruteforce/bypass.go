@@ -0,0 +1,211 @@
+package bruteforce
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// WithBypass re-tests 401 and 403 hits with path normalization and header tricks
+func WithBypass(enabled bool) Option {
+	return func(s *scannerImpl) {
+		s.bypass = enabled
+	}
+}
+
+// blocked checks if result is access denied hit worth bypass probes
+func blocked(result types.BruteResult) bool {
+	return result.Error == "" && !result.Wildcard &&
+		(result.StatusCode == http.StatusUnauthorized || result.StatusCode == http.StatusForbidden)
+}
+
+// bypassVariant request variation of blocked path
+type bypassVariant struct {
+	name    string
+	path    string
+	headers map[string]string
+}
+
+// localHeaders headers some proxies trust to tell request comes from localhost
+var localHeaders = []string{
+	"X-Forwarded-For",
+	"X-Real-IP",
+	"X-Client-IP",
+	"X-Remote-IP",
+	"X-Remote-Addr",
+	"X-Originating-IP",
+	"X-Custom-IP-Authorization",
+	"True-Client-IP",
+}
+
+// bypassVariants returns variations of escaped path p, order does not depend on p
+func bypassVariants(p string) []bypassVariant {
+	trimmed := strings.TrimSuffix(p, "/")
+	dir, last := "", strings.TrimPrefix(trimmed, "/")
+	if i := strings.LastIndex(trimmed, "/"); i >= 0 {
+		dir, last = trimmed[:i], trimmed[i+1:]
+	}
+
+	variants := []bypassVariant{
+		{name: "trailing slash", path: trimmed + "/"},
+		{name: "trailing dot segment", path: trimmed + "/."},
+		{name: "leading dot segment", path: dir + "/./" + last},
+		{name: "double slash", path: dir + "//" + last},
+		{name: "double leading slash", path: "/" + trimmed},
+		{name: "encoded dot segment", path: dir + "/%2e/" + last},
+		{name: "encoded last char", path: dir + "/" + encodeLast(last)},
+		{name: "semicolon", path: dir + "/;/" + last},
+		{name: "dot dot semicolon", path: trimmed + "..;/"},
+		{name: "trailing space", path: trimmed + "%20"},
+		{name: "trailing tab", path: trimmed + "%09"},
+		{name: "upper case", path: dir + "/" + strings.ToUpper(last)},
+		{name: "capitalized", path: dir + "/" + capitalize(last)},
+		{name: "X-Original-URL", path: "/", headers: map[string]string{"X-Original-URL": p}},
+		{name: "X-Rewrite-URL", path: "/", headers: map[string]string{"X-Rewrite-URL": p}},
+	}
+
+	for _, header := range localHeaders {
+		variants = append(variants, bypassVariant{
+			name:    header + ": 127.0.0.1",
+			path:    p,
+			headers: map[string]string{header: "127.0.0.1"},
+		})
+	}
+	variants = append(variants,
+		bypassVariant{name: "Forwarded: for=127.0.0.1", path: p, headers: map[string]string{"Forwarded": "for=127.0.0.1"}},
+		bypassVariant{name: "X-Forwarded-Host: localhost", path: p, headers: map[string]string{"X-Forwarded-Host": "localhost"}},
+	)
+
+	return variants
+}
+
+// probeBypass reports variants of blocked path that get successful response
+func (s *scannerImpl) probeBypass(ctx context.Context, run *scanRun, t task, blocked types.BruteResult) {
+	u, err := url.Parse(blocked.URL)
+	if err != nil {
+		return
+	}
+
+	original := u.EscapedPath()
+	if original == "" {
+		original = "/"
+	}
+
+	// control path with same shape shows if variant succeeds anywhere, e.g. X-Original-URL routed to index
+	control := original[:strings.LastIndex(strings.TrimSuffix(original, "/"), "/")+1] + randomWord()
+
+	variants := bypassVariants(original)
+	controls := bypassVariants(control)
+
+	seen := map[string]bool{original: true}
+	for i, v := range variants {
+		if ctx.Err() != nil {
+			return
+		}
+
+		key := v.path
+		for k, val := range v.headers {
+			key += "\n" + k + ": " + val
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		req, err := bypassRequest(ctx, t.method, u, v)
+		if err != nil || !s.scope.Allows(req.URL.String()) {
+			continue
+		}
+		// dump before sending, client adds auth and session headers to request headers
+		request := dumpRequest(req, v)
+		result := s.test(req)
+		if result.Error != "" || result.StatusCode < 200 || result.StatusCode >= 300 {
+			continue
+		}
+
+		if controlReq, err := bypassRequest(ctx, t.method, u, controls[i]); err == nil {
+			c := s.test(controlReq)
+			if c.Error == "" && c.StatusCode == result.StatusCode {
+				base := &Baseline{Probes: []Fingerprint{fingerprint(c)}}
+				if base.matches(fingerprint(result)) {
+					continue
+				}
+			}
+		}
+
+		result.Finding = types.FindingAccessBypass
+		result.Bypass = v.name
+		result.Parent = blocked.URL
+		result.Request = request
+		result.Depth = t.depth
+		result.Word = t.base
+		result.Variant = t.variant
+		s.store(run, result)
+	}
+}
+
+// bypassRequest builds request of variant keeping scheme, host and query of u
+func bypassRequest(ctx context.Context, method string, u *url.URL, v bypassVariant) (*http.Request, error) {
+	rawURL := u.Scheme + "://" + u.Host + v.path
+	if u.RawQuery != "" {
+		rawURL += "?" + u.RawQuery
+	}
+
+	// parsed URL keeps dot segments and escapes of path as generated
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "GoBruteScanner/1.0")
+	req.Header.Set("Accept", "*/*")
+	for k, val := range v.headers {
+		req.Header.Set(k, val)
+	}
+
+	return req, nil
+}
+
+// dumpRequest formats request line of variant, host and headers
+func dumpRequest(req *http.Request, v bypassVariant) string {
+	target := v.path
+	if req.URL.RawQuery != "" {
+		target += "?" + req.URL.RawQuery
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\r\nHost: %s\r\n", req.Method, target, req.URL.Host)
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\r\n", name, req.Header.Get(name))
+	}
+
+	return b.String()
+}
+
+// encodeLast percent-encodes last character of segment
+func encodeLast(segment string) string {
+	if segment == "" {
+		return segment
+	}
+	return segment[:len(segment)-1] + fmt.Sprintf("%%%02X", segment[len(segment)-1])
+}
+
+// capitalize upper-cases first letter of segment
+func capitalize(segment string) string {
+	for i, r := range segment {
+		return segment[:i] + string(unicode.ToUpper(r)) + segment[i+len(string(r)):]
+	}
+	return segment
+}
@@ -0,0 +1,265 @@
+package bruteforce
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// calibrationProbes random paths requested per method and directory
+const calibrationProbes = 3
+
+// Fingerprint response features compared against baseline
+type Fingerprint struct {
+	StatusCode int    `json:"status_code"`
+	Size       int    `json:"size"`
+	Words      int    `json:"words"`
+	Lines      int    `json:"lines"`
+	Hash       string `json:"hash"`
+	Location   string `json:"location,omitempty"`
+}
+
+// Baseline responses to random non-existent paths for method, directory and variant suffix
+type Baseline struct {
+	Method  string        `json:"method"`
+	Dir     string        `json:"dir"`
+	Variant string        `json:"variant,omitempty"`
+	Probes  []Fingerprint `json:"probes"`
+}
+
+// calibration baselines by method and directory
+type calibration struct {
+	mu        sync.RWMutex
+	baselines map[string]*Baseline
+}
+
+// newCalibration creates empty calibration
+func newCalibration() *calibration {
+	return &calibration{
+		baselines: make(map[string]*Baseline),
+	}
+}
+
+// calibrate probes random paths for every method, directory and variant suffix
+func (s *scannerImpl) calibrate(ctx context.Context, baseURL string, dirs, methods []string, concurrency int) *calibration {
+	cal := newCalibration()
+	variants := s.variantSuffixes()
+
+	type probe struct {
+		dir     string
+		method  string
+		variant string
+	}
+
+	probes := make(chan probe, len(dirs)*len(methods)*len(variants))
+	for _, dir := range dirs {
+		for _, method := range methods {
+			for _, variant := range variants {
+				probes <- probe{dir: dir, method: method, variant: variant}
+			}
+		}
+	}
+	close(probes)
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for p := range probes {
+				if ctx.Err() != nil {
+					return
+				}
+
+				if baseline := s.pathBaseline(ctx, baseURL, p.dir, p.method, p.variant); baseline != nil {
+					cal.add(baseline)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	return cal
+}
+
+// pathBaseline requests random paths in dir
+func (s *scannerImpl) pathBaseline(ctx context.Context, baseURL, dir, method, variant string) *Baseline {
+	return baseline(method, dir, variant, func(word string) types.BruteResult {
+		return s.testEndpoint(ctx, joinURL(baseURL, word), method)
+	})
+}
+
+// baseline collects fingerprints of random words, nil if every probe failed
+func baseline(method, dir, variant string, test func(word string) types.BruteResult) *Baseline {
+	baseline := &Baseline{Method: method, Dir: dir, Variant: variant}
+	for i := 0; i < calibrationProbes; i++ {
+		word := dir + randomWord() + variant
+		result := test(word)
+		if result.Error != "" {
+			continue
+		}
+		baseline.Probes = append(baseline.Probes, fingerprint(result, word))
+	}
+
+	if len(baseline.Probes) == 0 {
+		return nil
+	}
+	return baseline
+}
+
+// probe calibrates dir found while scanning
+func (c *calibration) probe(ctx context.Context, s *scannerImpl, baseURL, dir string, methods []string) {
+	for _, method := range methods {
+		for _, variant := range s.variantSuffixes() {
+			c.mu.RLock()
+			_, ok := c.baselines[baselineKey(method, dir, variant)]
+			c.mu.RUnlock()
+
+			if ok {
+				continue
+			}
+			if baseline := s.pathBaseline(ctx, baseURL, dir, method, variant); baseline != nil {
+				c.add(baseline)
+			}
+		}
+	}
+}
+
+// add stores baseline
+func (c *calibration) add(baseline *Baseline) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.baselines[baselineKey(baseline.Method, baseline.Dir, baseline.Variant)] = baseline
+}
+
+// Baselines returns all baselines
+func (c *calibration) Baselines() []Baseline {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	baselines := make([]Baseline, 0, len(c.baselines))
+	for _, b := range c.baselines {
+		baselines = append(baselines, *b)
+	}
+	return baselines
+}
+
+// isWildcard checks if result for words looks like baseline of its directory and variant
+func (c *calibration) isWildcard(result types.BruteResult, words []string, dir, variant string) bool {
+	if c == nil || result.Error != "" {
+		return false
+	}
+
+	c.mu.RLock()
+	baseline, ok := c.baselines[baselineKey(result.Method, dir, variant)]
+	c.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	return baseline.matches(fingerprint(result, words...))
+}
+
+// matches compares fingerprint with probes of same status
+func (b *Baseline) matches(fp Fingerprint) bool {
+	var probes []Fingerprint
+	for _, p := range b.Probes {
+		if p.StatusCode == fp.StatusCode {
+			probes = append(probes, p)
+		}
+	}
+	if len(probes) == 0 {
+		return false
+	}
+
+	sizeStable, wordsStable, linesStable := true, true, true
+	for _, p := range probes {
+		if p.Hash == fp.Hash {
+			return true
+		}
+		if p.Location != "" && p.Location == fp.Location {
+			return true
+		}
+		sizeStable = sizeStable && p.Size == probes[0].Size
+		wordsStable = wordsStable && p.Words == probes[0].Words
+		linesStable = linesStable && p.Lines == probes[0].Lines
+	}
+
+	switch {
+	case sizeStable:
+		return fp.Size == probes[0].Size
+	case wordsStable:
+		return fp.Words == probes[0].Words
+	case linesStable:
+		return fp.Lines == probes[0].Lines
+	}
+	return false
+}
+
+// fingerprint builds fingerprint with requested words removed from body and Location
+func fingerprint(result types.BruteResult, words ...string) Fingerprint {
+	body := result.Body
+	location := result.Headers["Location"]
+	for _, word := range words {
+		if word != "" {
+			body = strings.ReplaceAll(body, word, "")
+			location = strings.ReplaceAll(location, word, "")
+		}
+	}
+
+	hash := sha256.Sum256([]byte(body))
+
+	return Fingerprint{
+		StatusCode: result.StatusCode,
+		Size:       len(body),
+		Words:      countWords(body),
+		Lines:      countLines(body),
+		Hash:       hex.EncodeToString(hash[:]),
+		Location:   location,
+	}
+}
+
+// countWords returns number of whitespace separated words
+func countWords(body string) int {
+	return len(strings.Fields(body))
+}
+
+// countLines returns number of lines
+func countLines(body string) int {
+	if body == "" {
+		return 0
+	}
+	return strings.Count(body, "\n") + 1
+}
+
+// dirOf returns directory part of word with trailing slash
+func dirOf(word string) string {
+	word = strings.TrimPrefix(word, "/")
+	i := strings.LastIndex(strings.TrimSuffix(word, "/"), "/")
+	if i < 0 {
+		return ""
+	}
+	return word[:i+1]
+}
+
+// baselineKey key of baseline map
+func baselineKey(method, dir, variant string) string {
+	return method + " " + dir + " " + variant
+}
+
+// randomWord returns random path segment that should not exist
+func randomWord() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,356 @@
+package bruteforce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// CheckpointVersion format version of checkpoint files
+const CheckpointVersion = 1
+
+// DefaultCheckpointInterval time between periodic checkpoints
+const DefaultCheckpointInterval = 30 * time.Second
+
+// Checkpoint state of interrupted wordlist scan
+type Checkpoint struct {
+	Version  int       `json:"version"`
+	Saved    time.Time `json:"saved"`
+	Complete bool      `json:"complete"`
+	BaseURL  string    `json:"base_url"`
+	Wordlist []string  `json:"wordlist"`
+	Methods  []string  `json:"methods"`
+	// Urgent tasks run before Tasks, Queued counts derived tasks queued so far against max tasks
+	Urgent []TaskState `json:"urgent,omitempty"`
+	Tasks  []TaskState `json:"tasks"`
+	Queued int         `json:"queued"`
+
+	// Dropped derived tasks over max tasks
+	Dropped int `json:"dropped,omitempty"`
+
+	// Results kept so far without Body, for streamed scans ones not emitted yet
+	Results []types.BruteResult `json:"results"`
+
+	Calibrated        bool                `json:"calibrated"`
+	Baselines         []Baseline          `json:"baselines,omitempty"`
+	ArtifactBaselines []Baseline          `json:"artifact_baselines,omitempty"`
+	Recursed          []string            `json:"recursed,omitempty"`
+	Probed            []string            `json:"probed,omitempty"`
+	Allowed           map[string][]string `json:"allowed,omitempty"`
+
+	// Stats filled by caller
+	Stats types.Stats `json:"stats"`
+}
+
+// TaskState queued request of checkpoint
+type TaskState struct {
+	URL     string `json:"url"`
+	Method  string `json:"method"`
+	Word    string `json:"word"`
+	Base    string `json:"base"`
+	Variant string `json:"variant,omitempty"`
+	Depth   int    `json:"depth,omitempty"`
+	Expand  bool   `json:"expand,omitempty"`
+	Parent  string `json:"parent,omitempty"`
+
+	// Hit .git/HEAD artifact of git dump task
+	Hit *types.BruteResult `json:"hit,omitempty"`
+}
+
+// WithCheckpoint calls save with scan state every interval, on Pause and when wordlist scan ends,
+// interval <= 0 keeps DefaultCheckpointInterval
+func WithCheckpoint(interval time.Duration, save func(*Checkpoint) error) Option {
+	return func(s *scannerImpl) {
+		if interval <= 0 {
+			interval = DefaultCheckpointInterval
+		}
+		s.checkpointInterval = interval
+		s.saveCheckpoint = save
+	}
+}
+
+// LoadCheckpoint reads checkpoint file
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	if cp.Version != CheckpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d", cp.Version)
+	}
+	return &cp, nil
+}
+
+// Save writes checkpoint to path through temporary file so crash never leaves it half written
+func (cp *Checkpoint) Save(path string) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// ResumeWordlist continues wordlist scan from checkpoint, scanner options should match interrupted scan,
+// returned results include ones saved in checkpoint
+func (s *scannerImpl) ResumeWordlist(ctx context.Context, cp *Checkpoint, concurrency int, delay time.Duration) ([]types.BruteResult, error) {
+	run := restoreRun(cp, s.maxTasks)
+	results := run.results
+	if !cp.Complete {
+		results = s.execute(ctx, run, concurrency, delay)
+	}
+	if s.methodDiscovery {
+		run.applyMethods(results)
+	}
+	return results, run.saveErr
+}
+
+// restoreRun rebuilds scan state of checkpoint
+func restoreRun(cp *Checkpoint, maxTasks int) *scanRun {
+	run := newScanRun(cp.BaseURL, cp.Wordlist, cp.Methods, maxTasks)
+	run.checkpoint = true
+	run.results = cp.Results
+
+	if cp.Calibrated {
+		run.cal = newCalibration()
+		for i := range cp.Baselines {
+			run.cal.add(&cp.Baselines[i])
+		}
+	}
+	if len(cp.ArtifactBaselines) > 0 {
+		run.artifactCal = newCalibration()
+		for i := range cp.ArtifactBaselines {
+			run.artifactCal.add(&cp.ArtifactBaselines[i])
+		}
+	}
+
+	for _, prefix := range cp.Recursed {
+		run.recursed[prefix] = true
+	}
+	for _, key := range cp.Probed {
+		run.probed[key] = true
+	}
+	for url, methods := range cp.Allowed {
+		set := make(map[string]bool, len(methods))
+		for _, method := range methods {
+			set[method] = true
+		}
+		run.allowed[url] = set
+	}
+
+	run.tasks.restore(restoreTasks(cp.Urgent), restoreTasks(cp.Tasks), cp.Queued, cp.Dropped)
+	return run
+}
+
+// checkpoint waits for running tasks to finish and captures run state
+func (s *scannerImpl) checkpoint(run *scanRun, complete bool) *Checkpoint {
+	run.step.Lock()
+	defer run.step.Unlock()
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	urgent, tasks, queued, dropped := run.tasks.snapshot()
+	// git dumps still downloading rerun first on resume
+	dumping := make([]task, 0, len(run.dumping))
+	for _, t := range run.dumping {
+		dumping = append(dumping, t)
+	}
+	sort.Slice(dumping, func(i, j int) bool { return dumping[i].word < dumping[j].word })
+	urgent = append(dumping, urgent...)
+	cp := &Checkpoint{
+		Version:    CheckpointVersion,
+		Saved:      time.Now(),
+		Complete:   complete,
+		BaseURL:    run.baseURL,
+		Wordlist:   run.wordlist,
+		Methods:    run.methods,
+		Urgent:     taskStates(urgent),
+		Tasks:      taskStates(tasks),
+		Queued:     queued,
+		Dropped:    dropped,
+		Results:    checkpointResults(run.results, run.pending),
+		Calibrated: run.cal != nil,
+		Recursed:   sortedKeys(run.recursed),
+		Probed:     sortedKeys(run.probed),
+		Allowed:    make(map[string][]string, len(run.allowed)),
+	}
+	if run.cal != nil {
+		cp.Baselines = run.cal.Baselines()
+	}
+	if run.artifactCal != nil {
+		cp.ArtifactBaselines = run.artifactCal.Baselines()
+	}
+	for url := range run.allowed {
+		cp.Allowed[url] = run.allowedMethods(url)
+	}
+	return cp
+}
+
+// save hands run checkpoint to saveCheckpoint, first error is kept in run
+func (s *scannerImpl) save(run *scanRun, complete bool) error {
+	if !run.checkpoint || s.saveCheckpoint == nil {
+		return nil
+	}
+
+	err := s.saveCheckpoint(s.checkpoint(run, complete))
+	if err != nil {
+		run.mu.Lock()
+		if run.saveErr == nil {
+			run.saveErr = fmt.Errorf("checkpoint failed: %w", err)
+		}
+		run.mu.Unlock()
+	}
+	return err
+}
+
+// checkpointLoop saves run every checkpoint interval until done is closed
+func (s *scannerImpl) checkpointLoop(run *scanRun, done <-chan struct{}) {
+	ticker := time.NewTicker(s.checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.save(run, false)
+		}
+	}
+}
+
+// Pause stops workers from starting new tasks, running ones finish and active wordlist scans are checkpointed
+func (s *scannerImpl) Pause() error {
+	s.pauseMu.Lock()
+	if s.resume == nil {
+		s.resume = make(chan struct{})
+	}
+	runs := make([]*scanRun, 0, len(s.active))
+	for run := range s.active {
+		runs = append(runs, run)
+	}
+	s.pauseMu.Unlock()
+
+	var firstErr error
+	for _, run := range runs {
+		if err := s.save(run, false); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Resume lets paused workers continue
+func (s *scannerImpl) Resume() {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	if s.resume != nil {
+		close(s.resume)
+		s.resume = nil
+	}
+}
+
+// waitResumed blocks while scanner is paused, returns false if ctx is done first
+func (s *scannerImpl) waitResumed(ctx context.Context) bool {
+	s.pauseMu.Lock()
+	resume := s.resume
+	s.pauseMu.Unlock()
+
+	if resume == nil {
+		return true
+	}
+	select {
+	case <-resume:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// checkpointResults copies results with bodies stripped, bodies would be rewritten on every save
+func checkpointResults(lists ...[]types.BruteResult) []types.BruteResult {
+	var results []types.BruteResult
+	for _, list := range lists {
+		for _, r := range list {
+			r.Body = ""
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// taskStates converts tasks to checkpoint form
+func taskStates(tasks []task) []TaskState {
+	states := make([]TaskState, len(tasks))
+	for i, t := range tasks {
+		states[i] = TaskState{
+			URL:     t.url,
+			Method:  t.method,
+			Word:    t.word,
+			Base:    t.base,
+			Variant: t.variant,
+			Depth:   t.depth,
+			Expand:  t.expand,
+			Parent:  t.parent,
+			Hit:     t.hit,
+		}
+	}
+	return states
+}
+
+// restoreTasks converts checkpoint tasks back
+func restoreTasks(states []TaskState) []task {
+	tasks := make([]task, len(states))
+	for i, st := range states {
+		tasks[i] = task{
+			url:     st.URL,
+			method:  st.Method,
+			word:    st.Word,
+			base:    st.Base,
+			variant: st.Variant,
+			depth:   st.Depth,
+			expand:  st.Expand,
+			parent:  st.Parent,
+			hit:     st.Hit,
+		}
+	}
+	return tasks
+}
+
+// sortedKeys returns set keys in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,237 @@
+package bruteforce
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// Matcher checks bruteforce result
+type Matcher interface {
+	Match(result types.BruteResult) bool
+}
+
+// MatcherFunc adapts function to Matcher
+type MatcherFunc func(result types.BruteResult) bool
+
+// Match calls f
+func (f MatcherFunc) Match(result types.BruteResult) bool {
+	return f(result)
+}
+
+// Any matches if one of matchers matches
+func Any(matchers ...Matcher) Matcher {
+	return MatcherFunc(func(result types.BruteResult) bool {
+		for _, m := range matchers {
+			if m.Match(result) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// All matches if every matcher matches
+func All(matchers ...Matcher) Matcher {
+	return MatcherFunc(func(result types.BruteResult) bool {
+		for _, m := range matchers {
+			if !m.Match(result) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Not inverts matcher
+func Not(m Matcher) Matcher {
+	return MatcherFunc(func(result types.BruteResult) bool {
+		return !m.Match(result)
+	})
+}
+
+// Status matches status codes, spec is list like "200,204,301-399" or "all"
+func Status(spec string) (Matcher, error) {
+	if strings.TrimSpace(spec) == "all" {
+		return MatcherFunc(func(result types.BruteResult) bool {
+			return result.StatusCode > 0
+		}), nil
+	}
+	return rangeMatcher(spec, func(r types.BruteResult) int { return r.StatusCode })
+}
+
+// Size matches body size in bytes, spec is list like "0,100-200,>1000"
+func Size(spec string) (Matcher, error) {
+	return rangeMatcher(spec, func(r types.BruteResult) int { return r.Size })
+}
+
+// Words matches body word count
+func Words(spec string) (Matcher, error) {
+	return rangeMatcher(spec, func(r types.BruteResult) int { return r.Words })
+}
+
+// Lines matches body line count
+func Lines(spec string) (Matcher, error) {
+	return rangeMatcher(spec, func(r types.BruteResult) int { return r.Lines })
+}
+
+// ResponseTime matches response time in milliseconds, spec is list like "<500" or "100-300"
+func ResponseTime(spec string) (Matcher, error) {
+	return rangeMatcher(spec, func(r types.BruteResult) int { return int(r.Duration / time.Millisecond) })
+}
+
+// BodyRegex matches body by regular expression
+func BodyRegex(pattern string) (Matcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body regex: %w", err)
+	}
+	return MatcherFunc(func(result types.BruteResult) bool {
+		return re.MatchString(result.Body)
+	}), nil
+}
+
+// HeaderRegex matches "Name: value" header lines by regular expression
+func HeaderRegex(pattern string) (Matcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid header regex: %w", err)
+	}
+	return MatcherFunc(func(result types.BruteResult) bool {
+		for k, v := range result.Headers {
+			if re.MatchString(k + ": " + v) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// NewMatchers builds match and filter matchers from cfg, nil means not configured
+func NewMatchers(cfg types.MatchConfig) (Matcher, Matcher, error) {
+	match, err := buildMatchers(cfg.MatchStatus, cfg.MatchSize, cfg.MatchWords, cfg.MatchLines,
+		cfg.MatchRegex, cfg.MatchHeader, cfg.MatchTime)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid matcher: %w", err)
+	}
+	filter, err := buildMatchers(cfg.FilterStatus, cfg.FilterSize, cfg.FilterWords, cfg.FilterLines,
+		cfg.FilterRegex, cfg.FilterHeader, cfg.FilterTime)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	var matcher, filterer Matcher
+	if len(match) > 0 {
+		if strings.EqualFold(cfg.MatchMode, "and") {
+			matcher = All(match...)
+		} else {
+			matcher = Any(match...)
+		}
+	}
+	if len(filter) > 0 {
+		if strings.EqualFold(cfg.FilterMode, "and") {
+			filterer = All(filter...)
+		} else {
+			filterer = Any(filter...)
+		}
+	}
+	return matcher, filterer, nil
+}
+
+// buildMatchers creates matcher for every non-empty spec
+func buildMatchers(status, size, words, lines, body, header, duration string) ([]Matcher, error) {
+	builders := []struct {
+		spec  string
+		build func(string) (Matcher, error)
+	}{
+		{status, Status},
+		{size, Size},
+		{words, Words},
+		{lines, Lines},
+		{body, BodyRegex},
+		{header, HeaderRegex},
+		{duration, ResponseTime},
+	}
+
+	var matchers []Matcher
+	for _, b := range builders {
+		if b.spec == "" {
+			continue
+		}
+		m, err := b.build(b.spec)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// intRange inclusive range
+type intRange struct {
+	min, max int
+}
+
+// rangeMatcher matches value against list of ranges
+func rangeMatcher(spec string, value func(types.BruteResult) int) (Matcher, error) {
+	ranges, err := parseRanges(spec)
+	if err != nil {
+		return nil, err
+	}
+	return MatcherFunc(func(result types.BruteResult) bool {
+		v := value(result)
+		for _, r := range ranges {
+			if v >= r.min && v <= r.max {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+// parseRanges parses "1,5-10,>100,<3" list
+func parseRanges(spec string) ([]intRange, error) {
+	var ranges []intRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var r intRange
+		var err error
+		switch {
+		case strings.HasPrefix(part, ">"):
+			r.min, err = strconv.Atoi(strings.TrimSpace(part[1:]))
+			r.min++
+			r.max = math.MaxInt
+		case strings.HasPrefix(part, "<"):
+			r.max, err = strconv.Atoi(strings.TrimSpace(part[1:]))
+			r.max--
+			r.min = math.MinInt
+		case strings.Contains(part[1:], "-"):
+			i := strings.Index(part[1:], "-") + 1
+			r.min, err = strconv.Atoi(strings.TrimSpace(part[:i]))
+			if err == nil {
+				r.max, err = strconv.Atoi(strings.TrimSpace(part[i+1:]))
+			}
+		default:
+			r.min, err = strconv.Atoi(part)
+			r.max = r.min
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q", part)
+		}
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("empty range list %q", spec)
+	}
+	return ranges, nil
+}
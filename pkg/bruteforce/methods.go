@@ -0,0 +1,174 @@
+package bruteforce
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// DefaultExtraMethods methods tried beyond requested ones on paths that may accept them
+var DefaultExtraMethods = []string{http.MethodPatch, http.MethodHead, http.MethodTrace}
+
+// WithMethodDiscovery sends OPTIONS to every path first and queues methods from its Allow or Access-Control-Allow-Methods,
+// paths without them are requested with single method and other methods are sent only to existing paths:
+// requested and extra methods on 405, requested methods otherwise, empty extra keeps DefaultExtraMethods
+func WithMethodDiscovery(enabled bool, extra ...string) Option {
+	return func(s *scannerImpl) {
+		s.methodDiscovery = enabled
+		if len(extra) == 0 {
+			s.extraMethods = DefaultExtraMethods
+			return
+		}
+
+		s.extraMethods = nil
+		for _, method := range extra {
+			if method = strings.ToUpper(strings.TrimSpace(method)); method != "" {
+				s.extraMethods = append(s.extraMethods, method)
+			}
+		}
+	}
+}
+
+// probeMethod returns method every path is requested with in method discovery, GET if requested
+func probeMethod(methods []string) string {
+	for _, method := range methods {
+		if method == http.MethodGet {
+			return method
+		}
+	}
+	return methods[0]
+}
+
+// discoverMethods sends OPTIONS to path and queues methods it allows, false if answer does not list them
+func (s *scannerImpl) discoverMethods(ctx context.Context, run *scanRun, t task) bool {
+	options := s.testEndpoint(ctx, t.url, http.MethodOptions)
+	if options.Error != "" || options.StatusCode == http.StatusNotFound ||
+		options.StatusCode == http.StatusMethodNotAllowed || options.StatusCode == http.StatusNotImplemented ||
+		run.cal.isWildcard(options, []string{t.word}, t.dir(), t.variant) {
+		return false
+	}
+
+	allowed := allowHeader(options.Headers)
+	if len(allowed) == 0 {
+		return false
+	}
+	run.allowMethods(options.URL, allowed...)
+
+	seen := map[string]bool{http.MethodOptions: true}
+	for _, method := range allowed {
+		if seen[method] {
+			continue
+		}
+		seen[method] = true
+
+		next := t
+		next.method = method
+		next.expand = false
+		if !run.tasks.push(next) {
+			break
+		}
+	}
+	return true
+}
+
+// expandMethods queues other methods of path that answered probe method without OPTIONS listing them
+func (s *scannerImpl) expandMethods(run *scanRun, t task, result types.BruteResult) {
+	if !exists(result) {
+		return
+	}
+
+	allowed := allowHeader(result.Headers)
+
+	var methods []string
+	switch {
+	case len(allowed) > 0:
+		methods = allowed
+		run.allowMethods(result.URL, allowed...)
+	case result.StatusCode == http.StatusMethodNotAllowed:
+		methods = append(append([]string(nil), run.methods...), s.extraMethods...)
+	default:
+		methods = run.methods
+	}
+
+	seen := map[string]bool{t.method: true, http.MethodOptions: true}
+	for _, method := range methods {
+		if seen[method] {
+			continue
+		}
+		seen[method] = true
+
+		next := t
+		next.method = method
+		next.expand = false
+		if !run.tasks.push(next) {
+			return
+		}
+	}
+}
+
+// calibrationMethods adds OPTIONS to methods calibrated when method discovery sends it
+func (s *scannerImpl) calibrationMethods(methods []string) []string {
+	if !s.methodDiscovery {
+		return methods
+	}
+	for _, method := range methods {
+		if method == http.MethodOptions {
+			return methods
+		}
+	}
+	return append(append([]string(nil), methods...), http.MethodOptions)
+}
+
+// observeMethod marks method allowed on path if response does not reject it
+func (run *scanRun) observeMethod(result types.BruteResult) {
+	switch {
+	case result.Error != "", result.Wildcard:
+	case result.StatusCode == http.StatusNotFound, result.StatusCode == http.StatusMethodNotAllowed,
+		result.StatusCode == http.StatusNotImplemented:
+	default:
+		run.allowMethods(result.URL, result.Method)
+	}
+}
+
+// allowMethods adds methods to inferred method set of url
+func (run *scanRun) allowMethods(url string, methods ...string) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	set, ok := run.allowed[url]
+	if !ok {
+		set = make(map[string]bool)
+		run.allowed[url] = set
+	}
+	for _, method := range methods {
+		set[method] = true
+	}
+}
+
+// applyMethods stores inferred method set on every result of its url
+func (run *scanRun) applyMethods(results []types.BruteResult) {
+	for i := range results {
+		if methods := run.allowedMethods(results[i].URL); methods != nil {
+			results[i].Methods = methods
+		}
+	}
+}
+
+// allowHeader parses Allow and Access-Control-Allow-Methods
+func allowHeader(headers map[string]string) []string {
+	var methods []string
+	seen := make(map[string]bool)
+	for _, name := range []string{"Allow", "Access-Control-Allow-Methods"} {
+		for _, method := range strings.Split(headers[name], ",") {
+			method = strings.ToUpper(strings.TrimSpace(method))
+			if method == "" || method == "*" || seen[method] {
+				continue
+			}
+			seen[method] = true
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
@@ -0,0 +1,118 @@
+package bruteforce
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// DefaultOverrideMethods methods tunneled through POST by override probes
+var DefaultOverrideMethods = []string{http.MethodDelete, http.MethodPut, http.MethodPatch}
+
+// overrideTechniques ways to pass effective method, "header:" sets header, "query:" and "form:" set parameter
+var overrideTechniques = []string{
+	"header:X-HTTP-Method-Override",
+	"header:X-HTTP-Method",
+	"header:X-Method-Override",
+	"query:_method",
+	"form:_method",
+}
+
+// WithMethodOverride probes every confirmed path with POST carrying override headers and _method parameters,
+// empty methods keeps DefaultOverrideMethods
+func WithMethodOverride(enabled bool, methods ...string) Option {
+	return func(s *scannerImpl) {
+		s.methodOverride = enabled
+		s.overrideMethods = DefaultOverrideMethods
+		if len(methods) > 0 {
+			s.overrideMethods = methods
+		}
+	}
+}
+
+// exists checks if result confirms path
+func exists(result types.BruteResult) bool {
+	return result.Error == "" && !result.Wildcard && result.StatusCode != http.StatusNotFound
+}
+
+// probeOverrides reports techniques that make POST to task path behave like another method
+func (s *scannerImpl) probeOverrides(ctx context.Context, run *scanRun, t task) {
+	// plain POST is requested twice so dynamic content is not taken for override
+	plain := &Baseline{Method: http.MethodPost}
+	for i := 0; i < 2; i++ {
+		result := s.testOverride(ctx, t.url, "", "")
+		if result.Error == "" {
+			plain.Probes = append(plain.Probes, fingerprint(result))
+		}
+	}
+	if len(plain.Probes) == 0 {
+		return
+	}
+
+	for _, technique := range overrideTechniques {
+		// random verb shows if technique is reflected or ignored rather than honored
+		verb := strings.ToUpper(randomWord())
+		control := s.testOverride(ctx, t.url, technique, verb)
+		if control.Error != "" {
+			continue
+		}
+		controlFP := fingerprint(control, verb)
+
+		for _, method := range s.overrideMethods {
+			if ctx.Err() != nil {
+				return
+			}
+
+			result := s.testOverride(ctx, t.url, technique, method)
+			if result.Error != "" {
+				continue
+			}
+
+			fp := fingerprint(result, method)
+			if plain.matches(fp) || (fp.StatusCode == controlFP.StatusCode && fp.Hash == controlFP.Hash) {
+				continue
+			}
+
+			result.Finding = types.FindingMethodOverride
+			result.EffectiveMethod = method
+			result.Override = technique
+			result.Depth = t.depth
+			result.Word = t.base
+			result.Variant = t.variant
+			s.store(run, result)
+		}
+	}
+}
+
+// testOverride sends POST with method passed by technique, empty technique sends plain POST
+func (s *scannerImpl) testOverride(ctx context.Context, rawURL, technique, method string) types.BruteResult {
+	kind, name, _ := strings.Cut(technique, ":")
+
+	form := url.Values{}
+	if kind == "form" {
+		form.Set(name, method)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return types.BruteResult{URL: rawURL, Method: http.MethodPost, Error: err.Error()}
+	}
+
+	req.Header.Set("User-Agent", "GoBruteScanner/1.0")
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	switch kind {
+	case "header":
+		req.Header.Set(name, method)
+	case "query":
+		query := req.URL.Query()
+		query.Set(name, method)
+		req.URL.RawQuery = query.Encode()
+	}
+
+	return s.test(req)
+}
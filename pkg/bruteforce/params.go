@@ -0,0 +1,362 @@
+package bruteforce
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// DefaultParamBatch parameter names sent in one request
+const DefaultParamBatch = 32
+
+// WithParamBatch sets how many parameter names are sent in one request
+func WithParamBatch(n int) Option {
+	return func(s *scannerImpl) {
+		s.paramBatch = n
+	}
+}
+
+// ScanParams sends batches of parameter names to endpoint in query, form and JSON body,
+// batches that change response are bisected down to single parameters which are added to Metadata["params"]
+func (s *scannerImpl) ScanParams(ctx context.Context, endpoint types.Endpoint, names []string, concurrency int) (types.Endpoint, error) {
+	if endpoint.Method == "" {
+		endpoint.Method = http.MethodGet
+	}
+	if _, err := url.Parse(endpoint.URL); err != nil {
+		return endpoint, fmt.Errorf("invalid endpoint URL: %w", err)
+	}
+	if !s.scope.Allows(endpoint.URL) {
+		s.outOfScope.Add(1)
+		return endpoint, fmt.Errorf("endpoint %s is out of scope", endpoint.URL)
+	}
+
+	size := s.paramBatch
+	if size <= 0 {
+		size = DefaultParamBatch
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	inputs := formInputs(endpoint)
+	names = candidateParams(names, inputs)
+
+	locations := []string{types.ParamQuery}
+	if hasBody(endpoint.Method) {
+		locations = append(locations, types.ParamForm, types.ParamJSON)
+	}
+
+	type batch struct {
+		probe *paramProbe
+		names []string
+	}
+
+	var batches []batch
+	for _, location := range locations {
+		probe := &paramProbe{s: s, endpoint: endpoint, location: location, inputs: inputs}
+		if !probe.calibrate(ctx) {
+			continue
+		}
+		for start := 0; start < len(names); start += size {
+			end := min(start+size, len(names))
+			batches = append(batches, batch{probe: probe, names: names[start:end]})
+		}
+	}
+
+	jobs := make(chan batch, len(batches))
+	for _, b := range batches {
+		jobs <- b
+	}
+	close(jobs)
+
+	var mu sync.Mutex
+	found := make(map[string]*types.Parameter)
+	record := func(p types.Parameter) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := p.Location + " " + p.Name
+		if existing, ok := found[key]; ok {
+			existing.Reasons = mergeReasons(existing.Reasons, p.Reasons)
+			return
+		}
+		found[key] = &p
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+				b.probe.bisect(ctx, b.names, record)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return endpoint, ctx.Err()
+	}
+
+	params := make([]types.Parameter, 0, len(found))
+	for _, p := range found {
+		params = append(params, *p)
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].Location != params[j].Location {
+			return params[i].Location < params[j].Location
+		}
+		return params[i].Name < params[j].Name
+	})
+
+	metadata := make(map[string]interface{}, len(endpoint.Metadata)+1)
+	for k, v := range endpoint.Metadata {
+		metadata[k] = v
+	}
+	if existing, ok := metadata["params"].([]types.Parameter); ok {
+		params = append(existing, params...)
+	}
+	metadata["params"] = params
+	endpoint.Metadata = metadata
+
+	return endpoint, nil
+}
+
+// paramProbe sends parameter batches to endpoint in single location
+type paramProbe struct {
+	s        *scannerImpl
+	endpoint types.Endpoint
+	location string
+	inputs   map[string]string
+
+	base *Baseline
+	// echo endpoint reflects any parameter, reflection says nothing
+	echo bool
+}
+
+// calibrate requests endpoint with random parameter, false if every probe failed
+func (p *paramProbe) calibrate(ctx context.Context) bool {
+	p.base = baseline(p.endpoint.Method, "", "", func(word string) types.BruteResult {
+		result := p.send(ctx, map[string]string{word: word})
+		if strings.Contains(result.Body, word) {
+			p.echo = true
+		}
+		return result
+	})
+	return p.base != nil
+}
+
+// bisect finds parameters of batch that change response
+func (p *paramProbe) bisect(ctx context.Context, names []string, record func(types.Parameter)) {
+	if len(names) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		values[name] = randomWord()
+	}
+
+	result := p.send(ctx, values)
+	if result.Error != "" {
+		if len(names) > 1 {
+			p.split(ctx, names, record)
+		}
+		return
+	}
+
+	if !p.echo {
+		for _, name := range names {
+			if strings.Contains(result.Body, values[name]) {
+				record(types.Parameter{Name: name, Location: p.location, Reasons: []string{"reflected"}})
+			}
+		}
+	}
+
+	reasons := p.diff(result, values)
+	if len(reasons) == 0 {
+		return
+	}
+	if len(names) > 1 {
+		p.split(ctx, names, record)
+		return
+	}
+
+	// single parameter is confirmed with fresh value so flaky responses are not reported
+	confirm := map[string]string{names[0]: randomWord()}
+	if len(p.diff(p.send(ctx, confirm), confirm)) == 0 {
+		return
+	}
+	record(types.Parameter{Name: names[0], Location: p.location, Reasons: reasons})
+}
+
+// split bisects both halves of names
+func (p *paramProbe) split(ctx context.Context, names []string, record func(types.Parameter)) {
+	mid := len(names) / 2
+	p.bisect(ctx, names[:mid], record)
+	p.bisect(ctx, names[mid:], record)
+}
+
+// diff returns how result differs from baseline, values are removed from body before comparing
+func (p *paramProbe) diff(result types.BruteResult, values map[string]string) []string {
+	if result.Error != "" {
+		return nil
+	}
+
+	fp := fingerprint(result, payloadWords(values)...)
+	if p.base.matches(fp) {
+		return nil
+	}
+
+	for _, probe := range p.base.Probes {
+		if probe.StatusCode == fp.StatusCode {
+			return []string{"content"}
+		}
+	}
+	return []string{"status"}
+}
+
+// send requests endpoint with params in probe location
+func (p *paramProbe) send(ctx context.Context, params map[string]string) types.BruteResult {
+	req, err := p.request(ctx, params)
+	if err != nil {
+		return types.BruteResult{
+			URL:    p.endpoint.URL,
+			Method: p.endpoint.Method,
+			Error:  fmt.Sprintf("failed to create request: %v", err),
+		}
+	}
+	return p.s.test(req)
+}
+
+// request builds request with params and known form inputs
+func (p *paramProbe) request(ctx context.Context, params map[string]string) (*http.Request, error) {
+	u, err := url.Parse(p.endpoint.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var body string
+	var contentType string
+
+	switch p.location {
+	case types.ParamQuery:
+		query := u.Query()
+		for k, v := range p.inputs {
+			query.Set(k, v)
+		}
+		for k, v := range params {
+			query.Set(k, v)
+		}
+		u.RawQuery = query.Encode()
+
+	case types.ParamForm:
+		form := url.Values{}
+		for k, v := range p.inputs {
+			form.Set(k, v)
+		}
+		for k, v := range params {
+			form.Set(k, v)
+		}
+		body = form.Encode()
+		contentType = "application/x-www-form-urlencoded"
+
+	case types.ParamJSON:
+		object := make(map[string]string, len(p.inputs)+len(params))
+		for k, v := range p.inputs {
+			object[k] = v
+		}
+		for k, v := range params {
+			object[k] = v
+		}
+		data, err := json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		body = string(data)
+		contentType = "application/json"
+	}
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, p.endpoint.Method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "GoBruteScanner/1.0")
+	req.Header.Set("Accept", "*/*")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return req, nil
+}
+
+// formInputs returns inputs of form endpoint found by crawler
+func formInputs(endpoint types.Endpoint) map[string]string {
+	inputs, _ := endpoint.Metadata["inputs"].(map[string]string)
+	return inputs
+}
+
+// candidateParams returns unique names that are not known inputs
+func candidateParams(names []string, inputs map[string]string) []string {
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := inputs[name]; ok {
+			continue
+		}
+		seen[name] = true
+		unique = append(unique, name)
+	}
+	return unique
+}
+
+// hasBody checks if method usually carries body
+func hasBody(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	return true
+}
+
+// mergeReasons appends reasons missing in a
+func mergeReasons(a, b []string) []string {
+	for _, reason := range b {
+		if !contains(a, reason) {
+			a = append(a, reason)
+		}
+	}
+	return a
+}
+
+// contains checks if list has value
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package bruteforce
+
+import "sync"
+
+// queue task queue that workers can extend while scanning
+type queue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	space    *sync.Cond
+	tasks    []task
+	urgent   []task
+	inFlight int
+	queued   int
+	limit    int
+	dropped  int
+	closed   bool
+}
+
+// newQueue creates queue, limit caps tasks derived from results (recursion, urgent probes),
+// limit <= 0 means unlimited
+func newQueue(limit int) *queue {
+	q := &queue{limit: limit}
+	q.cond = sync.NewCond(&q.mu)
+	q.space = sync.NewCond(&q.mu)
+	return q
+}
+
+// push adds task, recursion tasks over limit are dropped and counted, returns false once queue is closed
+func (q *queue) push(t task) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return false
+	}
+	if t.depth > 0 && !q.admit() {
+		return true
+	}
+
+	q.tasks = append(q.tasks, t)
+	q.cond.Signal()
+	return true
+}
+
+// pushUrgent adds task popped before regular ones, tasks over limit are dropped and counted,
+// returns false once queue is closed
+func (q *queue) pushUrgent(t task) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return false
+	}
+	if !q.admit() {
+		return true
+	}
+
+	q.urgent = append(q.urgent, t)
+	q.cond.Signal()
+	return true
+}
+
+// pushFirst adds task ahead of urgent ones without counting it against limit,
+// returns false once queue is closed
+func (q *queue) pushFirst(t task) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return false
+	}
+
+	q.urgent = append([]task{t}, q.urgent...)
+	q.cond.Signal()
+	return true
+}
+
+// feed adds task from producer running along workers, blocks while backlog tasks wait,
+// returns false once queue is closed
+func (q *queue) feed(t task, backlog int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.tasks) >= backlog && !q.closed {
+		q.space.Wait()
+	}
+	if q.closed {
+		return false
+	}
+
+	q.tasks = append(q.tasks, t)
+	q.cond.Signal()
+	return true
+}
+
+// hold keeps workers waiting for tasks until matching done, e.g. while producer feeds queue
+func (q *queue) hold() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.inFlight++
+}
+
+// admit counts derived task against limit, caller holds mu
+func (q *queue) admit() bool {
+	if q.limit > 0 && q.queued >= q.limit {
+		q.dropped++
+		return false
+	}
+	q.queued++
+	return true
+}
+
+// pop returns next task, blocks while other workers may still add tasks
+func (q *queue) pop() (task, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.len() == 0 && q.inFlight > 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if q.len() == 0 || q.closed {
+		return task{}, false
+	}
+
+	var t task
+	if len(q.urgent) > 0 {
+		t = q.urgent[0]
+		q.urgent = q.urgent[1:]
+	} else {
+		t = q.tasks[0]
+		q.tasks = q.tasks[1:]
+		q.space.Signal()
+	}
+	q.inFlight++
+	return t, true
+}
+
+// requeue puts back popped task that was interrupted, it runs first on resume
+func (q *queue) requeue(t task) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.tasks = append([]task{t}, q.tasks...)
+}
+
+// snapshot returns copies of waiting tasks, number of derived tasks queued and dropped so far
+func (q *queue) snapshot() (urgent, tasks []task, queued, dropped int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]task(nil), q.urgent...), append([]task(nil), q.tasks...), q.queued, q.dropped
+}
+
+// restore fills empty queue with checkpointed tasks
+func (q *queue) restore(urgent, tasks []task, queued, dropped int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.urgent = urgent
+	q.tasks = tasks
+	q.queued = queued
+	q.dropped = dropped
+}
+
+// truncated returns number of tasks dropped over limit
+func (q *queue) truncated() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.dropped
+}
+
+// done marks popped task as finished
+func (q *queue) done() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.inFlight--
+	if q.inFlight == 0 && q.len() == 0 {
+		q.cond.Broadcast()
+	}
+}
+
+// close stops queue, waiting workers return
+func (q *queue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+	q.space.Broadcast()
+}
+
+// len returns number of waiting tasks, caller holds mu
+func (q *queue) len() int {
+	return len(q.tasks) + len(q.urgent)
+}
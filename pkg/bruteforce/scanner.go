@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"path"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/httpclient"
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/scope"
 	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
 )
 
@@ -17,17 +22,161 @@ import (
 type Scanner interface {
 	ScanPath(ctx context.Context, url string, methods []string, delay time.Duration) ([]types.BruteResult, error)
 	ScanWordlist(ctx context.Context, baseURL string, wordlist []string, methods []string, concurrency int, delay time.Duration) ([]types.BruteResult, error)
+	StreamWordlist(ctx context.Context, baseURL string, wordlist []string, methods []string, concurrency int, delay time.Duration, fn ResultFunc) error
+	ResumeWordlist(ctx context.Context, cp *Checkpoint, concurrency int, delay time.Duration) ([]types.BruteResult, error)
+	ScanTemplate(ctx context.Context, tmpl types.RequestTemplate, wordlist []string, concurrency int, delay time.Duration) ([]types.BruteResult, error)
+	ScanAttack(ctx context.Context, tmpl types.RequestTemplate, payloads map[string][]string, mode AttackMode, concurrency int, delay time.Duration) ([]types.BruteResult, error)
+	ScanParams(ctx context.Context, endpoint types.Endpoint, names []string, concurrency int) (types.Endpoint, error)
+	ScanVhosts(ctx context.Context, baseURL, domain string, names []string, concurrency int, delay time.Duration) ([]types.BruteResult, error)
+	Pause() error
+	Resume()
+	Truncated() int
+	OutOfScope() int
 }
 
+// Option to configure bruteforcer
+type Option func(*scannerImpl)
+
 // scannerImpl implements Scanner interface
 type scannerImpl struct {
-	client types.HTTPClient
+	client      types.HTTPClient
+	calibration bool
+	matcher     Matcher
+	filter      Matcher
+
+	recursionDepth  int
+	recursionStatus []int
+	maxTasks        int
+
+	extensions []string
+	suffixes   []string
+
+	paramBatch int
+
+	methodDiscovery bool
+	extraMethods    []string
+
+	methodOverride  bool
+	overrideMethods []string
+
+	bypass    bool
+	artifacts bool
+
+	gitDump    bool
+	gitDumpDir string
+
+	scope *scope.Scope
+
+	checkpointInterval time.Duration
+	saveCheckpoint     func(*Checkpoint) error
+
+	// resume is open while paused, active runs are checkpointed on pause
+	pauseMu sync.Mutex
+	resume  chan struct{}
+	active  map[*scanRun]bool
+	// truncated derived tasks dropped over max tasks by finished runs
+	truncated int
+	// outOfScope words and endpoints skipped by scope, e.g. logout paths
+	outOfScope atomic.Int64
 }
 
 // NewScanner creates bruteforcer
-func NewScanner(client types.HTTPClient) Scanner {
-	return &scannerImpl{
-		client: client,
+func NewScanner(client types.HTTPClient, opts ...Option) Scanner {
+	s := &scannerImpl{
+		client:          client,
+		calibration:     true,
+		recursionStatus: DefaultRecursionStatus,
+		maxTasks:        DefaultMaxTasks,
+		active:          make(map[*scanRun]bool),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// WithCalibration enables wildcard and soft-404 calibration before scan
+func WithCalibration(enabled bool) Option {
+	return func(s *scannerImpl) {
+		s.calibration = enabled
+	}
+}
+
+// DefaultRecursionStatus status codes that mark directory for recursion
+var DefaultRecursionStatus = []int{200, 204, 301, 302, 307, 308, 401, 403}
+
+// WithRecursion scans wordlist again under found directories up to depth,
+// statusCodes trigger recursion of directory-like hits, empty keeps DefaultRecursionStatus
+func WithRecursion(depth int, statusCodes []int) Option {
+	return func(s *scannerImpl) {
+		s.recursionDepth = depth
+		if len(statusCodes) > 0 {
+			s.recursionStatus = statusCodes
+		}
+	}
+}
+
+// DefaultMaxTasks cap of tasks derived from results
+const DefaultMaxTasks = 100000
+
+// WithMaxTasks caps queued tasks derived from results: recursion and git tracked paths,
+// dropped ones are counted by Truncated, wordlist and payload tasks and inline artifact probes are never capped,
+// n <= 0 means unlimited
+func WithMaxTasks(n int) Option {
+	return func(s *scannerImpl) {
+		s.maxTasks = n
+	}
+}
+
+// WithExtensions expands every word with extensions like "php" or ".json"
+func WithExtensions(extensions ...string) Option {
+	return func(s *scannerImpl) {
+		s.extensions = nil
+		for _, ext := range extensions {
+			ext = strings.TrimSpace(ext)
+			if ext == "" {
+				continue
+			}
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			s.extensions = append(s.extensions, ext)
+		}
+	}
+}
+
+// WithSuffixes expands every word and extension variant with suffixes like "~" or ".old"
+func WithSuffixes(suffixes ...string) Option {
+	return func(s *scannerImpl) {
+		s.suffixes = nil
+		for _, suffix := range suffixes {
+			if suffix = strings.TrimSpace(suffix); suffix != "" {
+				s.suffixes = append(s.suffixes, suffix)
+			}
+		}
+	}
+}
+
+// WithScope skips wordlist and probe requests to URLs out of scope, templates are sent as given
+func WithScope(sc *scope.Scope) Option {
+	return func(s *scannerImpl) {
+		s.scope = sc
+	}
+}
+
+// WithMatcher keeps only results matched by m
+func WithMatcher(m Matcher) Option {
+	return func(s *scannerImpl) {
+		s.matcher = m
+	}
+}
+
+// WithFilter drops results matched by m
+func WithFilter(m Matcher) Option {
+	return func(s *scannerImpl) {
+		s.filter = m
 	}
 }
 
@@ -45,9 +194,11 @@ func (s *scannerImpl) ScanPath(ctx context.Context, url string, methods []string
 
 			result := s.testEndpoint(ctx, url, m)
 
-			mu.Lock()
-			results = append(results, result)
-			mu.Unlock()
+			if s.keep(result) {
+				mu.Lock()
+				results = append(results, result)
+				mu.Unlock()
+			}
 
 			time.Sleep(delay)
 		}(method)
@@ -57,23 +208,95 @@ func (s *scannerImpl) ScanPath(ctx context.Context, url string, methods []string
 	return results, nil
 }
 
-// ScanWordlist scans path list
+// ScanWordlist scans path list, checkpoint error is returned with results
 func (s *scannerImpl) ScanWordlist(ctx context.Context, baseURL string, wordlist []string, methods []string, concurrency int, delay time.Duration) ([]types.BruteResult, error) {
-	// Создаем канал задач
-	tasks := make(chan task, len(wordlist)*len(methods))
-	for _, path := range wordlist {
-		fullURL := strings.TrimRight(baseURL, "/") + "/" + strings.TrimPrefix(path, "/")
-		for _, method := range methods {
-			tasks <- task{
-				url:    fullURL,
-				method: method,
-			}
-		}
+	run := newScanRun(baseURL, wordlist, methods, s.maxTasks)
+	run.checkpoint = true
+	if s.calibration {
+		run.cal = s.calibrate(ctx, baseURL, directories(wordlist), s.calibrationMethods(methods), concurrency)
+	}
+
+	s.enqueue(run.tasks, baseURL, "", wordlist, methods, 0)
+
+	results := s.execute(ctx, run, concurrency, delay)
+	if s.methodDiscovery {
+		run.applyMethods(results)
+	}
+	return results, run.saveErr
+}
+
+// scanRun state of single scan
+type scanRun struct {
+	baseURL  string
+	wordlist []string
+	methods  []string
+	tasks    *queue
+	cal      *calibration
+
+	mu      sync.Mutex
+	results []types.BruteResult
+	// recursed prefixes, recursion is queued once per directory
+	recursed map[string]bool
+	// allowed inferred method sets by url
+	allowed map[string]map[string]bool
+	// probed keys of per-path probes already run
+	probed map[string]bool
+	// artifactCal baselines of backup suffixes, taken on first candidate hit
+	artifactCal *calibration
+
+	// emit receives kept results instead of results when set, err is its first error,
+	// pending results wait for emit outside step so callbacks may Pause
+	emit    ResultFunc
+	emitMu  sync.Mutex
+	err     error
+	pending []types.BruteResult
+
+	// checkpoint marks wordlist scans saved by saveCheckpoint, step is held by workers
+	// while they run task so checkpoint can wait for consistent state
+	checkpoint bool
+	step       sync.RWMutex
+	saveErr    error
+
+	// concurrency of workers, dumping git dump tasks running outside step by directory
+	concurrency int
+	dumping     map[string]task
+}
+
+// newScanRun creates scan state
+func newScanRun(baseURL string, wordlist, methods []string, maxTasks int) *scanRun {
+	return &scanRun{
+		baseURL:  baseURL,
+		wordlist: wordlist,
+		methods:  methods,
+		tasks:    newQueue(maxTasks),
+		recursed: make(map[string]bool),
+		allowed:  make(map[string]map[string]bool),
+		probed:   make(map[string]bool),
+		dumping:  make(map[string]task),
+	}
+}
+
+// execute runs workers until queue is empty or ctx is done
+func (s *scannerImpl) execute(ctx context.Context, run *scanRun, concurrency int, delay time.Duration) []types.BruteResult {
+	stop := context.AfterFunc(ctx, run.tasks.close)
+	defer stop()
+
+	run.concurrency = concurrency
+
+	s.pauseMu.Lock()
+	s.active[run] = true
+	s.pauseMu.Unlock()
+
+	done, stopped := make(chan struct{}), make(chan struct{})
+	if run.checkpoint && s.saveCheckpoint != nil {
+		go func() {
+			defer close(stopped)
+			s.checkpointLoop(run, done)
+		}()
+	} else {
+		close(stopped)
 	}
-	close(tasks)
 
-	var results []types.BruteResult
-	var mu sync.Mutex
 	var wg sync.WaitGroup
 
 	for i := 0; i < concurrency; i++ {
@@ -81,39 +304,258 @@ func (s *scannerImpl) ScanWordlist(ctx context.Context, baseURL string, wordlist
 		go func(workerID int) {
 			defer wg.Done()
 
-			for task := range tasks {
-				select {
-				case <-ctx.Done():
+			for s.waitResumed(ctx) {
+				run.step.RLock()
+				task, ok := run.tasks.pop()
+				if !ok {
+					run.step.RUnlock()
 					return
-				default:
-					result := s.testEndpoint(ctx, task.url, task.method)
+				}
 
-					mu.Lock()
-					results = append(results, result)
-					mu.Unlock()
+				if task.hit != nil {
+					run.mu.Lock()
+					run.dumping[task.word] = task
+					run.mu.Unlock()
+					run.step.RUnlock()
 
+					s.dumpGit(ctx, run, task)
+					s.flush(run)
 					time.Sleep(delay)
+					continue
 				}
+
+				s.process(ctx, run, task)
+
+				run.tasks.done()
+				run.step.RUnlock()
+				s.flush(run)
+				time.Sleep(delay)
 			}
 		}(i)
 	}
 
 	wg.Wait()
-	return results, nil
+	s.flush(run)
+	close(done)
+	<-stopped
+
+	s.pauseMu.Lock()
+	delete(s.active, run)
+	s.truncated += run.tasks.truncated()
+	s.pauseMu.Unlock()
+
+	s.save(run, ctx.Err() == nil && run.err == nil)
+	return run.results
 }
 
-// testEndpoint tests endpoint
-func (s *scannerImpl) testEndpoint(ctx context.Context, url, method string) types.BruteResult {
-	result := types.BruteResult{
-		URL:       url,
-		Method:    method,
-		Timestamp: time.Now(),
+// Truncated returns number of derived tasks dropped over max tasks by finished and running scans
+func (s *scannerImpl) Truncated() int {
+	s.pauseMu.Lock()
+	defer s.pauseMu.Unlock()
+
+	n := s.truncated
+	for run := range s.active {
+		n += run.tasks.truncated()
+	}
+	return n
+}
+
+// OutOfScope returns number of words and endpoints skipped by scope
+func (s *scannerImpl) OutOfScope() int {
+	return int(s.outOfScope.Load())
+}
+
+// process runs single task and stores its result
+func (s *scannerImpl) process(ctx context.Context, run *scanRun, t task) {
+	if t.tmpl == nil && !s.scope.Allows(t.url) {
+		s.outOfScope.Add(1)
+		return
 	}
 
+	var result types.BruteResult
+	words := []string{t.word}
+	if t.tmpl != nil {
+		result = s.testTemplate(ctx, t.tmpl, t.payload)
+		words = payloadWords(t.payload)
+	} else {
+		if s.methodDiscovery && t.expand && s.discoverMethods(ctx, run, t) {
+			return
+		}
+		result = s.testEndpoint(ctx, t.url, t.method)
+	}
+	if ctx.Err() != nil {
+		run.tasks.requeue(t)
+		return
+	}
+
+	result.Wildcard = run.cal.isWildcard(result, words, t.dir(), t.variant)
+	result.Depth = t.depth
+	result.Word = t.base
+	result.Variant = t.variant
+	if t.parent != "" {
+		result.Parent = t.parent
+	}
+
+	if s.methodDiscovery && t.tmpl == nil {
+		run.observeMethod(result)
+		if t.expand {
+			s.expandMethods(run, t, result)
+		}
+	}
+
+	if s.methodOverride && t.tmpl == nil && exists(result) && run.once("override "+result.URL) {
+		s.probeOverrides(ctx, run, t)
+	}
+
+	if s.bypass && t.tmpl == nil && blocked(result) && run.once("bypass "+result.Method+" "+result.URL) {
+		s.probeBypass(ctx, run, t, result)
+	}
+
+	if s.artifacts && t.tmpl == nil && exists(result) && result.StatusCode >= 200 && result.StatusCode < 300 &&
+		run.once("artifacts "+result.URL) {
+		s.probeArtifacts(ctx, run, t, result)
+	}
+
+	if s.shouldRecurse(result, t) {
+		prefix := strings.TrimSuffix(t.base, "/") + "/"
+
+		run.mu.Lock()
+		fresh := !run.recursed[prefix]
+		run.recursed[prefix] = true
+		run.mu.Unlock()
+
+		if fresh {
+			if run.cal != nil {
+				run.cal.probe(ctx, s, run.baseURL, prefix, s.calibrationMethods(run.methods))
+			}
+			s.enqueue(run.tasks, run.baseURL, prefix, run.wordlist, run.methods, t.depth+1)
+		}
+	}
+
+	s.store(run, result)
+}
+
+// store adds result to run or emits it if matcher and filter keep it
+func (s *scannerImpl) store(run *scanRun, result types.BruteResult) {
+	if !s.keep(result) {
+		return
+	}
+	run.mu.Lock()
+	if run.emit != nil {
+		run.pending = append(run.pending, result)
+	} else {
+		run.results = append(run.results, result)
+	}
+	run.mu.Unlock()
+}
+
+// once checks if key is seen first time in run
+func (run *scanRun) once(key string) bool {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	if run.probed[key] {
+		return false
+	}
+	run.probed[key] = true
+	return true
+}
+
+// enqueue adds wordlist variants under prefix to queue
+func (s *scannerImpl) enqueue(tasks *queue, baseURL, prefix string, wordlist, methods []string, depth int) {
+	seen := make(map[string]bool)
+	variants := s.variantSuffixes()
+
+	expand := s.methodDiscovery && len(methods) > 0
+	if expand {
+		methods = []string{probeMethod(methods)}
+	}
+
+	for _, path := range wordlist {
+		base := prefix + strings.TrimPrefix(path, "/")
+		for _, variant := range variants {
+			if variant != "" && strings.HasSuffix(base, "/") {
+				continue
+			}
+
+			word := base + variant
+			if seen[word] {
+				continue
+			}
+			seen[word] = true
+
+			fullURL := joinURL(baseURL, word)
+			if !s.scope.Allows(fullURL) {
+				s.outOfScope.Add(1)
+				continue
+			}
+			for _, method := range methods {
+				t := task{url: fullURL, method: method, word: word, base: base, variant: variant, depth: depth, expand: expand}
+				if !tasks.push(t) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// variantSuffixes returns "" followed by extensions and suffixes applied to word and every extension
+func (s *scannerImpl) variantSuffixes() []string {
+	bases := append([]string{""}, s.extensions...)
+	variants := append([]string(nil), bases...)
+	for _, base := range bases {
+		for _, suffix := range s.suffixes {
+			variants = append(variants, base+suffix)
+		}
+	}
+
+	seen := make(map[string]bool, len(variants))
+	unique := variants[:0]
+	for _, v := range variants {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// shouldRecurse checks if result is directory worth scanning with wordlist again
+func (s *scannerImpl) shouldRecurse(result types.BruteResult, t task) bool {
+	if t.tmpl != nil || t.depth >= s.recursionDepth || t.variant != "" || result.Error != "" || result.Wildcard {
+		return false
+	}
+	for _, code := range s.recursionStatus {
+		if result.StatusCode == code {
+			return directoryLike(t.word, result)
+		}
+	}
+	return false
+}
+
+// directoryLike checks hit looks like directory: word with trailing slash or no extension, or redirect to word/
+func directoryLike(word string, result types.BruteResult) bool {
+	name := strings.TrimPrefix(word, "/")
+	if strings.HasSuffix(name, "/") || path.Ext(name) == "" {
+		return true
+	}
+	if result.StatusCode < 300 || result.StatusCode >= 400 {
+		return false
+	}
+	u, err := url.Parse(result.Headers["Location"])
+	return err == nil && strings.HasSuffix(u.Path, "/"+name+"/")
+}
+
+// testEndpoint tests endpoint
+func (s *scannerImpl) testEndpoint(ctx context.Context, url, method string) types.BruteResult {
 	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
-		result.Error = fmt.Sprintf("failed to create request: %v", err)
-		return result
+		return types.BruteResult{
+			URL:       url,
+			Method:    method,
+			Timestamp: time.Now(),
+			Error:     fmt.Sprintf("failed to create request: %v", err),
+		}
 	}
 
 	req.Header.Set("User-Agent", "GoBruteScanner/1.0")
@@ -122,8 +564,27 @@ func (s *scannerImpl) testEndpoint(ctx context.Context, url, method string) type
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	return s.test(req)
+}
+
+// test sends request and collects result
+func (s *scannerImpl) test(req *http.Request) (result types.BruteResult) {
+	result = types.BruteResult{
+		URL:       req.URL.String(),
+		Method:    req.Method,
+		Timestamp: time.Now(),
+	}
+
+	ctx := httpclient.WithAttemptCounter(req.Context())
+	req = req.WithContext(ctx)
+	defer func() {
+		result.Attempts = httpclient.Attempts(ctx)
+	}()
+
+	start := time.Now()
 	resp, err := s.client.Do(req)
 	if err != nil {
+		result.Duration = time.Since(start)
 		result.Error = fmt.Sprintf("request failed: %v", err)
 		return result
 	}
@@ -139,6 +600,7 @@ func (s *scannerImpl) testEndpoint(ctx context.Context, url, method string) type
 	}
 
 	body, err := io.ReadAll(resp.Body)
+	result.Duration = time.Since(start)
 	if err != nil {
 		result.Error = fmt.Sprintf("failed to read body: %v", err)
 		return result
@@ -146,6 +608,8 @@ func (s *scannerImpl) testEndpoint(ctx context.Context, url, method string) type
 
 	result.Body = string(body)
 	result.Size = len(body)
+	result.Words = countWords(result.Body)
+	result.Lines = countLines(result.Body)
 
 	if strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
 		doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
@@ -157,7 +621,57 @@ func (s *scannerImpl) testEndpoint(ctx context.Context, url, method string) type
 	return result
 }
 
+// keep checks result against matcher and filter
+func (s *scannerImpl) keep(result types.BruteResult) bool {
+	if s.matcher != nil && !s.matcher.Match(result) {
+		return false
+	}
+	if s.filter != nil && s.filter.Match(result) {
+		return false
+	}
+	return true
+}
+
 type task struct {
-	url    string
-	method string
+	url     string
+	method  string
+	word    string
+	base    string
+	variant string
+	depth   int
+	tmpl    *types.RequestTemplate
+	payload map[string]string
+	// expand sends OPTIONS first and queues other methods of path after it or this one answers
+	expand bool
+	// parent URL path was derived from, e.g. .git directory
+	parent string
+	// hit .git/HEAD artifact of repository at url that task dumps
+	hit *types.BruteResult
+}
+
+// dir returns calibration directory of task, templates are calibrated as a whole
+func (t task) dir() string {
+	if t.tmpl != nil {
+		return ""
+	}
+	return dirOf(t.word)
+}
+
+// joinURL appends path to base URL
+func joinURL(baseURL, path string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// directories returns unique directories of wordlist entries, root included
+func directories(wordlist []string) []string {
+	seen := map[string]bool{"": true}
+	dirs := []string{""}
+	for _, word := range wordlist {
+		dir := dirOf(word)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
 }
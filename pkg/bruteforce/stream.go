@@ -0,0 +1,78 @@
+package bruteforce
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// ResultFunc receives results as they complete, calls are serialized and block emitting worker until return,
+// callback may Pause scan, non-nil error stops scan and is returned by stream method
+type ResultFunc func(types.BruteResult) error
+
+// StreamWordlist scans path list like ScanWordlist but hands every kept result to fn instead of collecting them,
+// with method discovery Methods holds methods inferred by the time result is emitted
+func (s *scannerImpl) StreamWordlist(ctx context.Context, baseURL string, wordlist []string, methods []string, concurrency int, delay time.Duration, fn ResultFunc) error {
+	run := newScanRun(baseURL, wordlist, methods, s.maxTasks)
+	run.emit = fn
+	run.checkpoint = true
+	if s.calibration {
+		run.cal = s.calibrate(ctx, baseURL, directories(wordlist), s.calibrationMethods(methods), concurrency)
+	}
+
+	s.enqueue(run.tasks, baseURL, "", wordlist, methods, 0)
+
+	s.execute(ctx, run, concurrency, delay)
+	if run.err != nil {
+		return run.err
+	}
+	return run.saveErr
+}
+
+// flush hands pending results to run callback in order, first callback error closes queue
+func (s *scannerImpl) flush(run *scanRun) {
+	if run.emit == nil {
+		return
+	}
+	run.emitMu.Lock()
+	defer run.emitMu.Unlock()
+
+	for {
+		run.mu.Lock()
+		if len(run.pending) == 0 {
+			run.mu.Unlock()
+			return
+		}
+		result := run.pending[0]
+		run.pending = run.pending[1:]
+		if s.methodDiscovery {
+			result.Methods = run.allowedMethods(result.URL)
+		}
+		run.mu.Unlock()
+
+		if run.err != nil {
+			continue
+		}
+		if err := run.emit(result); err != nil {
+			run.err = err
+			run.tasks.close()
+		}
+	}
+}
+
+// allowedMethods returns sorted inferred method set of url, caller holds mu when workers run
+func (run *scanRun) allowedMethods(url string) []string {
+	set := run.allowed[url]
+	if len(set) == 0 {
+		return nil
+	}
+
+	methods := make([]string, 0, len(set))
+	for method := range set {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
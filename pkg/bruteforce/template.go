@@ -0,0 +1,316 @@
+package bruteforce
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/httpclient"
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// FuzzKeyword placeholder replaced with wordlist entries in request templates
+const FuzzKeyword = "FUZZ"
+
+// feedBacklog payload tasks generated ahead of workers
+const feedBacklog = 1000
+
+// AttackMode how wordlists of several keywords are combined
+type AttackMode string
+
+// Attack modes
+const (
+	// ModeClusterbomb tries every combination of all wordlists
+	ModeClusterbomb AttackMode = "clusterbomb"
+	// ModePitchfork takes i-th word of every wordlist together, stops at shortest list
+	ModePitchfork AttackMode = "pitchfork"
+	// ModeSniper fuzzes one keyword at a time, others get template defaults
+	ModeSniper AttackMode = "sniper"
+)
+
+// ScanTemplate fuzzes request template, FUZZ may appear in URL, header and cookie values and body
+func (s *scannerImpl) ScanTemplate(ctx context.Context, tmpl types.RequestTemplate, wordlist []string, concurrency int, delay time.Duration) ([]types.BruteResult, error) {
+	return s.ScanAttack(ctx, tmpl, map[string][]string{FuzzKeyword: wordlist}, ModeClusterbomb, concurrency, delay)
+}
+
+// ScanAttack fuzzes template with wordlist per keyword combined by mode
+func (s *scannerImpl) ScanAttack(ctx context.Context, tmpl types.RequestTemplate, payloads map[string][]string, mode AttackMode, concurrency int, delay time.Duration) ([]types.BruteResult, error) {
+	return s.attack(ctx, tmpl, payloads, mode, s.calibration, concurrency, delay)
+}
+
+// attack runs template fuzzing, calibrate overrides scanner setting for modes that rely on baseline
+func (s *scannerImpl) attack(ctx context.Context, tmpl types.RequestTemplate, payloads map[string][]string, mode AttackMode, calibrate bool, concurrency int, delay time.Duration) ([]types.BruteResult, error) {
+	if tmpl.Method == "" {
+		tmpl.Method = http.MethodGet
+	}
+	if len(payloads) == 0 {
+		return nil, fmt.Errorf("no wordlists for template")
+	}
+
+	keywords := make([]string, 0, len(payloads))
+	for keyword := range payloads {
+		if !hasKeyword(tmpl, keyword) {
+			return nil, fmt.Errorf("template has no %s keyword", keyword)
+		}
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	run := newScanRun("", nil, []string{tmpl.Method}, s.maxTasks)
+	if calibrate {
+		run.cal = newCalibration()
+		b := baseline(tmpl.Method, "", "", func(word string) types.BruteResult {
+			random := make(map[string]string, len(keywords))
+			for _, keyword := range keywords {
+				random[keyword] = word
+			}
+			return s.testTemplate(ctx, &tmpl, random)
+		})
+		if b != nil {
+			run.cal.add(b)
+		}
+	}
+
+	if mode == ModeClusterbomb || mode == "" {
+		// combinations of unique words are unique, no set of all of them is kept
+		payloads = uniqueLists(payloads)
+	} else if mode != ModePitchfork && mode != ModeSniper {
+		return nil, fmt.Errorf("unknown attack mode %q", mode)
+	}
+
+	// payloads are generated while workers run so clusterbomb of large lists never sits in memory
+	seen := make(map[string]bool)
+	run.tasks.hold()
+	go func() {
+		defer run.tasks.done()
+
+		combine(mode, keywords, payloads, tmpl.Defaults, func(payload map[string]string) bool {
+			if mode == ModePitchfork || mode == ModeSniper {
+				key := payloadKey(keywords, payload)
+				if seen[key] {
+					return true
+				}
+				seen[key] = true
+			}
+
+			t := task{method: tmpl.Method, payload: payload, tmpl: &tmpl}
+			if len(keywords) == 1 {
+				t.word = payload[keywords[0]]
+				t.base = t.word
+			}
+			return run.tasks.feed(t, feedBacklog)
+		})
+	}()
+
+	return s.execute(ctx, run, concurrency, delay), nil
+}
+
+// uniqueLists returns copy of lists with repeated words dropped, order is kept
+func uniqueLists(lists map[string][]string) map[string][]string {
+	unique := make(map[string][]string, len(lists))
+	for keyword, list := range lists {
+		seen := make(map[string]bool, len(list))
+		for _, word := range list {
+			if !seen[word] {
+				seen[word] = true
+				unique[keyword] = append(unique[keyword], word)
+			}
+		}
+	}
+	return unique
+}
+
+// combine emits payloads of mode until emit returns false
+func combine(mode AttackMode, keywords []string, lists map[string][]string, defaults map[string]string, emit func(map[string]string) bool) error {
+	switch mode {
+	case ModeClusterbomb, "":
+		for _, keyword := range keywords {
+			if len(lists[keyword]) == 0 {
+				return nil
+			}
+		}
+
+		indexes := make([]int, len(keywords))
+		for {
+			payload := make(map[string]string, len(keywords))
+			for i, keyword := range keywords {
+				payload[keyword] = lists[keyword][indexes[i]]
+			}
+			if !emit(payload) {
+				return nil
+			}
+
+			// odometer increment, last keyword changes fastest
+			i := len(keywords) - 1
+			for ; i >= 0; i-- {
+				indexes[i]++
+				if indexes[i] < len(lists[keywords[i]]) {
+					break
+				}
+				indexes[i] = 0
+			}
+			if i < 0 {
+				return nil
+			}
+		}
+
+	case ModePitchfork:
+		n := -1
+		for _, keyword := range keywords {
+			if n < 0 || len(lists[keyword]) < n {
+				n = len(lists[keyword])
+			}
+		}
+		for i := 0; i < n; i++ {
+			payload := make(map[string]string, len(keywords))
+			for _, keyword := range keywords {
+				payload[keyword] = lists[keyword][i]
+			}
+			if !emit(payload) {
+				return nil
+			}
+		}
+		return nil
+
+	case ModeSniper:
+		for _, keyword := range keywords {
+			for _, word := range lists[keyword] {
+				payload := make(map[string]string, len(keywords))
+				for _, other := range keywords {
+					payload[other] = defaults[other]
+				}
+				payload[keyword] = word
+				if !emit(payload) {
+					return nil
+				}
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown attack mode %q", mode)
+}
+
+// testTemplate renders template with payload and sends it
+func (s *scannerImpl) testTemplate(ctx context.Context, tmpl *types.RequestTemplate, payload map[string]string) types.BruteResult {
+	req, err := renderTemplate(ctx, tmpl, payload)
+	if err != nil {
+		return types.BruteResult{
+			URL:       payloadReplacer(payload).Replace(tmpl.URL),
+			Method:    tmpl.Method,
+			Timestamp: time.Now(),
+			Error:     fmt.Sprintf("failed to create request: %v", err),
+			Payload:   payload,
+		}
+	}
+
+	result := s.test(req)
+	result.Payload = payload
+	return result
+}
+
+// renderTemplate builds request with keywords replaced by payload values
+func renderTemplate(ctx context.Context, tmpl *types.RequestTemplate, payload map[string]string) (*http.Request, error) {
+	replacer := payloadReplacer(payload)
+
+	var body io.Reader
+	if tmpl.Body != "" {
+		body = strings.NewReader(replacer.Replace(tmpl.Body))
+	}
+
+	switch {
+	case tmpl.ServerName != "" && tmpl.InsecureServerName:
+		ctx = httpclient.WithInsecureServerName(ctx, replacer.Replace(tmpl.ServerName))
+	case tmpl.ServerName != "":
+		ctx = httpclient.WithServerName(ctx, replacer.Replace(tmpl.ServerName))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, replacer.Replace(tmpl.Method), replacer.Replace(tmpl.URL), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "GoBruteScanner/1.0")
+	req.Header.Set("Accept", "*/*")
+	for k, v := range tmpl.Headers {
+		if strings.EqualFold(k, "Host") {
+			req.Host = replacer.Replace(v)
+			continue
+		}
+		req.Header.Set(k, replacer.Replace(v))
+	}
+	for name, v := range tmpl.Cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: replacer.Replace(v)})
+	}
+
+	if tmpl.Body != "" && req.Header.Get("Content-Type") == "" {
+		trimmed := strings.TrimSpace(tmpl.Body)
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			req.Header.Set("Content-Type", "application/json")
+		} else {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+
+	return req, nil
+}
+
+// payloadReplacer replaces keywords, longer keywords first so FUZZ2 is not taken for FUZZ
+func payloadReplacer(payload map[string]string) *strings.Replacer {
+	keywords := make([]string, 0, len(payload))
+	for keyword := range payload {
+		keywords = append(keywords, keyword)
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		return len(keywords[i]) > len(keywords[j])
+	})
+
+	pairs := make([]string, 0, len(payload)*2)
+	for _, keyword := range keywords {
+		pairs = append(pairs, keyword, payload[keyword])
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// payloadKey unique key of payload
+func payloadKey(keywords []string, payload map[string]string) string {
+	values := make([]string, len(keywords))
+	for i, keyword := range keywords {
+		values[i] = payload[keyword]
+	}
+	return strings.Join(values, "\x00")
+}
+
+// payloadWords returns payload values
+func payloadWords(payload map[string]string) []string {
+	words := make([]string, 0, len(payload))
+	for _, word := range payload {
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// hasKeyword checks if keyword appears anywhere in template
+func hasKeyword(tmpl types.RequestTemplate, keyword string) bool {
+	if strings.Contains(tmpl.Method, keyword) || strings.Contains(tmpl.URL, keyword) ||
+		strings.Contains(tmpl.Body, keyword) || strings.Contains(tmpl.ServerName, keyword) {
+		return true
+	}
+	for _, v := range tmpl.Headers {
+		if strings.Contains(v, keyword) {
+			return true
+		}
+	}
+	for _, v := range tmpl.Cookies {
+		if strings.Contains(v, keyword) {
+			return true
+		}
+	}
+	return false
+}
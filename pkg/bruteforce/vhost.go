@@ -0,0 +1,54 @@
+package bruteforce
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// ScanVhosts requests baseURL with Host header and TLS SNI set to every name, names are subdomain labels of domain,
+// empty domain takes names as full hostnames, hosts answering like random host are marked as wildcard,
+// certificates are not verified since names are guesses
+func (s *scannerImpl) ScanVhosts(ctx context.Context, baseURL, domain string, names []string, concurrency int, delay time.Duration) ([]types.BruteResult, error) {
+	host := FuzzKeyword
+	if domain != "" {
+		host += "." + domain
+	}
+
+	labels := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if domain != "" {
+			name = strings.TrimSuffix(name, "."+domain)
+		}
+		if name != "" {
+			labels = append(labels, name)
+		}
+	}
+
+	tmpl := types.RequestTemplate{
+		Method:     http.MethodGet,
+		URL:        baseURL,
+		Headers:    map[string]string{"Host": host},
+		ServerName: host,
+		// random host baseline never matches certificate, verifying would leave vhosts without baseline
+		InsecureServerName: true,
+	}
+
+	// random host baseline is what tells vhosts apart, so it is taken even with calibration off
+	results, err := s.attack(ctx, tmpl, map[string][]string{FuzzKeyword: labels}, ModeClusterbomb, true, concurrency, delay)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].Host = strings.ReplaceAll(host, FuzzKeyword, results[i].Word)
+		if !results[i].Wildcard && results[i].Error == "" {
+			results[i].Finding = types.FindingVhost
+		}
+	}
+	return results, nil
+}
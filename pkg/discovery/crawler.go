@@ -2,6 +2,7 @@ package discovery
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -10,31 +11,83 @@ import (
 	"sync"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/scope"
 	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
 	"golang.org/x/net/html"
 )
 
+// DefaultWorkers pages fetched at once when no worker count is set
+const DefaultWorkers = 5
+
+// maxBodySize limit of page, robots.txt, sitemap and spec bodies read by crawler
+const maxBodySize = 50 << 20
+
 // Crawler to find endpoints
 type Crawler struct {
 	client    types.HTTPClient
 	maxDepth  int
+	workers   int
+	maxPages  int
+	scope     *scope.Scope
+	active    *scope.Scope
 	visited   map[string]bool
 	baseURL   *url.URL
 	mu        sync.RWMutex
 	endpoints []types.Endpoint
+
+	seeds       bool
+	honorRobots bool
+	robots      *robots
+
+	openAPI bool
 }
 
+// Option to configure crawler
+type Option func(*Crawler)
+
 // NewCrawler creates new crawler
-func NewCrawler(client types.HTTPClient, maxDepth int) *Crawler {
-	return &Crawler{
+func NewCrawler(client types.HTTPClient, maxDepth int, opts ...Option) *Crawler {
+	c := &Crawler{
 		client:    client,
 		maxDepth:  maxDepth,
+		workers:   DefaultWorkers,
 		visited:   make(map[string]bool),
 		endpoints: make([]types.Endpoint, 0),
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// WithWorkers sets number of pages fetched at once, n <= 0 keeps DefaultWorkers
+func WithWorkers(n int) Option {
+	return func(c *Crawler) {
+		if n > 0 {
+			c.workers = n
+		}
+	}
 }
 
-// Crawl recursive scan
+// WithMaxPages caps number of fetched pages, n <= 0 means unlimited
+func WithMaxPages(n int) Option {
+	return func(c *Crawler) {
+		c.maxPages = n
+	}
+}
+
+// WithScope limits requested URLs, out-of-scope ones are still recorded with scope metadata,
+// without scope only base host is crawled
+func WithScope(sc *scope.Scope) Option {
+	return func(c *Crawler) {
+		c.scope = sc
+	}
+}
+
+// Crawl breadth-first scan, every depth is fetched by worker pool before next one,
+// endpoints found so far are returned with ctx error when ctx is done
 func (c *Crawler) Crawl(ctx context.Context, baseURL string) ([]types.Endpoint, error) {
 	parsedURL, err := url.Parse(baseURL)
 	if err != nil {
@@ -42,48 +95,128 @@ func (c *Crawler) Crawl(ctx context.Context, baseURL string) ([]types.Endpoint,
 	}
 	c.baseURL = parsedURL
 
-	return c.crawlRecursive(ctx, "/", 0)
-}
+	c.active = c.scope
+	if c.active == nil {
+		if c.active, err = scope.New(baseURL, types.ScopeConfig{}); err != nil {
+			return nil, err
+		}
+	}
 
-// crawlRecursive recursive scan
-func (c *Crawler) crawlRecursive(ctx context.Context, path string, depth int) ([]types.Endpoint, error) {
-	if depth > c.maxDepth {
-		return c.endpoints, nil
+	root := c.resolvePath("/")
+	if ok, reason := c.active.Check(root); !ok {
+		return nil, fmt.Errorf("base URL is out of scope: %s", reason)
 	}
+	c.mu.Lock()
+	c.visited[root] = true
+	c.mu.Unlock()
 
+	var seeds []string
+	if c.seeds || c.honorRobots {
+		seeds = c.seed(ctx)
+	}
+	if c.openAPI {
+		c.probeSpecs(ctx)
+	}
 	c.mu.Lock()
-	if _, visited := c.visited[path]; visited {
-		c.mu.Unlock()
-		return c.endpoints, nil
+	unvisited := seeds[:0]
+	for _, seed := range seeds {
+		if !c.visited[seed] {
+			c.visited[seed] = true
+			unvisited = append(unvisited, seed)
+		}
 	}
-	c.visited[path] = true
+	seeds = unvisited
 	c.mu.Unlock()
 
-	fullURL := c.resolvePath(path)
+	frontier := []string{root}
+	pages := 0
+	for depth := 0; depth <= c.maxDepth && len(frontier) > 0; depth++ {
+		if c.maxPages > 0 {
+			if pages >= c.maxPages {
+				break
+			}
+			if len(frontier) > c.maxPages-pages {
+				frontier = frontier[:c.maxPages-pages]
+			}
+		}
+		pages += len(frontier)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
-	if err != nil {
-		return c.endpoints, err
+		next, err := c.crawlLevel(ctx, frontier, depth)
+		if ctx.Err() != nil {
+			return c.GetEndpoints(), ctx.Err()
+		}
+		if depth == 0 && err != nil {
+			return c.GetEndpoints(), err
+		}
+		if depth == 0 {
+			// robots.txt and sitemap URLs are one hop from root, after its links
+			next = append(next, seeds...)
+		}
+		frontier = next
 	}
-	req.Header.Set("User-Agent", "GoBruteScanner/1.0")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return c.endpoints, err
+	return c.GetEndpoints(), nil
+}
+
+// crawlLevel fetches frontier pages of depth, returns unvisited links in page order and first fetch error
+func (c *Crawler) crawlLevel(ctx context.Context, frontier []string, depth int) ([]string, error) {
+	links := make([][]string, len(frontier))
+	errs := make([]error, len(frontier))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers && i < len(frontier); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				links[j], errs[j] = c.crawlPage(ctx, frontier[j], depth)
+			}
+		}()
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+feed:
+	for j := range frontier {
+		select {
+		case jobs <- j:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	var next []string
+	var firstErr error
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for j := range frontier {
+		if errs[j] != nil && firstErr == nil {
+			firstErr = errs[j]
+		}
+		for _, link := range links[j] {
+			link, _, _ = strings.Cut(link, "#")
+			if !c.visited[link] && c.robotsAllowed(link) {
+				c.visited[link] = true
+				next = append(next, link)
+			}
+		}
+	}
+	return next, firstErr
+}
+
+// crawlPage fetches page, stores its endpoints and returns links to follow
+func (c *Crawler) crawlPage(ctx context.Context, fullURL string, depth int) ([]string, error) {
+	body, _, err := c.get(ctx, fullURL, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 	if err != nil {
-		return c.endpoints, err
+		return nil, err
 	}
 
 	bodyStr := string(body)
 
-	c.extractLinks(bodyStr, path, depth)
-	c.extractForms(bodyStr, path, depth)
-	c.extractFromJS(bodyStr, path, depth)
+	links := c.extractLinks(bodyStr, fullURL, depth)
+	c.extractForms(bodyStr, fullURL, depth)
+	c.extractFromJS(bodyStr, fullURL, depth)
 
 	c.mu.Lock()
 	c.endpoints = append(c.endpoints, types.Endpoint{
@@ -94,24 +227,29 @@ func (c *Crawler) crawlRecursive(ctx context.Context, path string, depth int) ([
 	})
 	c.mu.Unlock()
 
-	var wg sync.WaitGroup
-	c.mu.RLock()
-	endpointsCopy := make([]types.Endpoint, len(c.endpoints))
-	copy(endpointsCopy, c.endpoints)
-	c.mu.RUnlock()
-
-	for _, endpoint := range endpointsCopy {
-		if endpoint.Depth == depth && endpoint.Source == "link" {
-			wg.Add(1)
-			go func(urlPath string, d int) {
-				defer wg.Done()
-				c.crawlRecursive(ctx, urlPath, d+1)
-			}(endpoint.URL, depth)
-		}
+	return links, nil
+}
+
+// get fetches URL, returns body cut at maxBodySize and status
+func (c *Crawler) get(ctx context.Context, fullURL, accept string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
+	if err != nil {
+		return nil, 0, err
 	}
-	wg.Wait()
+	req.Header.Set("User-Agent", "GoBruteScanner/1.0")
+	req.Header.Set("Accept", accept)
 
-	return c.endpoints, nil
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
 }
 
 // GetEndpoints returns all found endpoints
@@ -139,14 +277,15 @@ func (c *Crawler) resolvePath(path string) string {
 	return resolved.String()
 }
 
-// extractLinks extracts links from html
-func (c *Crawler) extractLinks(htmlContent, currentPath string, depth int) {
+// extractLinks extracts links from html, returns their URLs
+func (c *Crawler) extractLinks(htmlContent, currentPath string, depth int) []string {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
 	if err != nil {
-		c.extractLinksWithTokenizer(htmlContent, currentPath, depth)
-		return
+		return c.extractLinksWithTokenizer(htmlContent, currentPath, depth)
 	}
 
+	var links []string
+
 	doc.Find("a").Each(func(i int, s *goquery.Selection) {
 		href, exists := s.Attr("href")
 		if !exists || href == "" {
@@ -162,32 +301,40 @@ func (c *Crawler) extractLinks(htmlContent, currentPath string, depth int) {
 			return
 		}
 
+		endpoint, ok := c.scoped(types.Endpoint{
+			URL:    normalized,
+			Method: "GET",
+			Source: "link",
+			Depth:  depth + 1,
+			Metadata: map[string]interface{}{
+				"text": s.Text(),
+			},
+		})
+		if ok {
+			links = append(links, normalized)
+		}
+
 		c.mu.Lock()
 		defer c.mu.Unlock()
 
 		if _, visited := c.visited[normalized]; !visited {
-			c.endpoints = append(c.endpoints, types.Endpoint{
-				URL:    normalized,
-				Method: "GET",
-				Source: "link",
-				Depth:  depth + 1,
-				Metadata: map[string]interface{}{
-					"text": s.Text(),
-				},
-			})
+			c.endpoints = append(c.endpoints, endpoint)
 		}
 	})
+
+	return links
 }
 
 // extractLinksWithTokenizer alt method to extarct links
-func (c *Crawler) extractLinksWithTokenizer(htmlContent, currentPath string, depth int) {
+func (c *Crawler) extractLinksWithTokenizer(htmlContent, currentPath string, depth int) []string {
 	tokenizer := html.NewTokenizer(strings.NewReader(htmlContent))
 
+	var links []string
 	for {
 		tt := tokenizer.Next()
 		switch tt {
 		case html.ErrorToken:
-			return
+			return links
 		case html.StartTagToken, html.SelfClosingTagToken:
 			t := tokenizer.Token()
 			if t.Data == "a" {
@@ -203,14 +350,19 @@ func (c *Crawler) extractLinksWithTokenizer(htmlContent, currentPath string, dep
 							continue
 						}
 
+						endpoint, ok := c.scoped(types.Endpoint{
+							URL:    normalized,
+							Method: "GET",
+							Source: "link",
+							Depth:  depth + 1,
+						})
+						if ok {
+							links = append(links, normalized)
+						}
+
 						c.mu.Lock()
 						if _, visited := c.visited[normalized]; !visited {
-							c.endpoints = append(c.endpoints, types.Endpoint{
-								URL:    normalized,
-								Method: "GET",
-								Source: "link",
-								Depth:  depth + 1,
-							})
+							c.endpoints = append(c.endpoints, endpoint)
 						}
 						c.mu.Unlock()
 					}
@@ -269,8 +421,7 @@ func (c *Crawler) extractForms(htmlContent, currentPath string, depth int) {
 			}
 		})
 
-		c.mu.Lock()
-		c.endpoints = append(c.endpoints, types.Endpoint{
+		endpoint, _ := c.scoped(types.Endpoint{
 			URL:    normalized,
 			Method: method,
 			Source: "form",
@@ -279,6 +430,9 @@ func (c *Crawler) extractForms(htmlContent, currentPath string, depth int) {
 				"inputs": inputs,
 			},
 		})
+
+		c.mu.Lock()
+		c.endpoints = append(c.endpoints, endpoint)
 		c.mu.Unlock()
 	})
 }
@@ -320,8 +474,7 @@ func (c *Crawler) extractFromJS(jsContent, currentPath string, depth int) {
 			if path != "" {
 				normalized := c.normalizeURL(path, currentPath)
 				if normalized != "" {
-					c.mu.Lock()
-					c.endpoints = append(c.endpoints, types.Endpoint{
+					endpoint, _ := c.scoped(types.Endpoint{
 						URL:    normalized,
 						Method: method,
 						Source: "javascript",
@@ -330,6 +483,9 @@ func (c *Crawler) extractFromJS(jsContent, currentPath string, depth int) {
 							"pattern": pattern.regex.String(),
 						},
 					})
+
+					c.mu.Lock()
+					c.endpoints = append(c.endpoints, endpoint)
 					c.mu.Unlock()
 				}
 			}
@@ -337,15 +493,26 @@ func (c *Crawler) extractFromJS(jsContent, currentPath string, depth int) {
 	}
 }
 
-// normalizeURL normalizes URL
+// scoped tags endpoint out of scope if it may not be requested, returns if it may
+func (c *Crawler) scoped(endpoint types.Endpoint) (types.Endpoint, bool) {
+	ok, reason := c.active.Check(endpoint.URL)
+	if !ok {
+		scope.Tag(&endpoint, reason)
+	}
+	return endpoint, ok
+}
+
+// normalizeURL normalizes URL, other hosts are kept for scope check
 func (c *Crawler) normalizeURL(href, currentPath string) string {
 	if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
-		parsed, err := url.Parse(href)
-		if err != nil || parsed.Host != c.baseURL.Host {
+		if _, err := url.Parse(href); err != nil {
 			return ""
 		}
 		return href
 	}
+	if strings.HasPrefix(href, "//") {
+		return c.baseURL.Scheme + ":" + href
+	}
 
 	if strings.HasPrefix(href, "mailto:") || strings.HasPrefix(href, "tel:") ||
 		strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
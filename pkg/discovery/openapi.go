@@ -0,0 +1,715 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/scope"
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSpecPaths well-known locations of OpenAPI and Swagger documents
+var DefaultSpecPaths = []string{
+	"/openapi.json",
+	"/openapi.yaml",
+	"/openapi.yml",
+	"/swagger.json",
+	"/swagger.yaml",
+	"/swagger.yml",
+	"/v3/api-docs",
+	"/v2/api-docs",
+	"/api-docs",
+	"/swagger/v1/swagger.json",
+	"/api/openapi.json",
+	"/api/swagger.json",
+	"/api/v1/swagger.json",
+}
+
+// specMethods operations of path item in output order
+var specMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// maxRefDepth nesting of resolved $ref, deeper and cyclic refs stay as {"$ref": ...}
+const maxRefDepth = 8
+
+// maxSpecNodes schema nodes copied per spec, further refs stay unexpanded
+const maxSpecNodes = 100000
+
+// WithOpenAPI probes DefaultSpecPaths and imports operations of found specs
+func WithOpenAPI(enabled bool) Option {
+	return func(c *Crawler) {
+		c.openAPI = enabled
+	}
+}
+
+// ParseSpec converts OpenAPI 3 or Swagger 2 document in JSON or YAML to endpoints, one per path and operation,
+// relative servers are resolved against specURL which is also used when spec names no host
+func ParseSpec(data []byte, specURL string) ([]types.Endpoint, error) {
+	doc, err := decodeSpec(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &spec{
+		doc:       doc,
+		expanded:  make(map[string]interface{}),
+		expanding: make(map[string]bool),
+		budget:    maxSpecNodes,
+	}
+	switch {
+	case strings.HasPrefix(stringField(doc, "openapi"), "3"):
+		s.version = stringField(doc, "openapi")
+	case strings.HasPrefix(stringField(doc, "swagger"), "2"):
+		s.version = stringField(doc, "swagger")
+		s.swagger = true
+	default:
+		return nil, fmt.Errorf("not an OpenAPI 2 or 3 document")
+	}
+
+	base, err := s.baseURL(specURL)
+	if err != nil {
+		return nil, err
+	}
+	return s.endpoints(base, specURL), nil
+}
+
+// LoadSpec reads local spec file, paths are resolved against baseURL when spec names no server
+func LoadSpec(path, baseURL string) ([]types.Endpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+
+	endpoints, err := ParseSpec(data, baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse spec %s: %w", path, err)
+	}
+	for i := range endpoints {
+		endpoints[i].Metadata["spec"] = path
+	}
+	return endpoints, nil
+}
+
+// ImportSpec loads local spec file like LoadSpec and tags endpoints outside crawler scope
+func (c *Crawler) ImportSpec(path, baseURL string) ([]types.Endpoint, error) {
+	endpoints, err := LoadSpec(path, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range endpoints {
+		if ok, reason := c.scope.Check(endpoints[i].URL); !ok {
+			scope.Tag(&endpoints[i], reason)
+		}
+	}
+	return endpoints, nil
+}
+
+// probeSpecs fetches DefaultSpecPaths and records spec documents with their operations
+func (c *Crawler) probeSpecs(ctx context.Context) {
+	seen := make(map[string]bool)
+	for _, path := range DefaultSpecPaths {
+		if ctx.Err() != nil {
+			return
+		}
+
+		specURL := c.baseURL.ResolveReference(&url.URL{Path: path}).String()
+		if !c.active.Allows(specURL) {
+			continue
+		}
+		body, status, err := c.get(ctx, specURL, "application/json,application/yaml,text/yaml,*/*")
+		if err != nil || status != http.StatusOK {
+			continue
+		}
+		endpoints, err := ParseSpec(body, specURL)
+		if err != nil {
+			continue
+		}
+
+		c.mu.Lock()
+		c.endpoints = append(c.endpoints, types.Endpoint{
+			URL:    specURL,
+			Method: "GET",
+			Source: "openapi",
+			Depth:  1,
+			Metadata: map[string]interface{}{
+				"spec_document": true,
+				"operations":    len(endpoints),
+			},
+		})
+		for _, endpoint := range endpoints {
+			// same API is often served under several spec paths
+			key := endpoint.Method + " " + endpoint.URL
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			endpoint, _ = c.scoped(endpoint)
+			c.endpoints = append(c.endpoints, endpoint)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// decodeSpec decodes JSON or YAML document into map
+func decodeSpec(data []byte) (map[string]interface{}, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var doc interface{}
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("{")) {
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		doc = jsonValue(doc)
+	}
+
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("spec is not an object")
+	}
+	return m, nil
+}
+
+// jsonValue converts decoded YAML to types encoding/json produces: string keys and float64 numbers
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = jsonValue(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = jsonValue(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonValue(item)
+		}
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return v
+}
+
+// spec decoded OpenAPI document
+type spec struct {
+	doc     map[string]interface{}
+	version string
+	swagger bool
+
+	// expanded schemas of refs by depth, expanding guards cycles, budget counts nodes left to copy
+	expanded  map[string]interface{}
+	expanding map[string]bool
+	budget    int
+}
+
+// baseURL returns API root from servers (OpenAPI 3) or schemes, host and basePath (Swagger 2)
+func (s *spec) baseURL(specURL string) (string, error) {
+	ref, err := url.Parse(specURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid spec URL: %w", err)
+	}
+
+	if s.swagger {
+		u := &url.URL{Scheme: ref.Scheme, Host: ref.Host, Path: stringField(s.doc, "basePath")}
+		if schemes := stringList(s.doc["schemes"]); len(schemes) > 0 && !containsFold(schemes, u.Scheme) {
+			u.Scheme = schemes[0]
+		}
+		if host := stringField(s.doc, "host"); host != "" {
+			u.Host = host
+		}
+		if u.Scheme == "" && u.Host != "" {
+			u.Scheme = "https"
+		}
+		return strings.TrimRight(u.String(), "/"), nil
+	}
+
+	server := "/"
+	if servers, ok := s.doc["servers"].([]interface{}); ok && len(servers) > 0 {
+		if first, ok := s.resolve(servers[0]).(map[string]interface{}); ok && stringField(first, "url") != "" {
+			server = stringField(first, "url")
+			vars, _ := first["variables"].(map[string]interface{})
+			for name, v := range vars {
+				if v, ok := v.(map[string]interface{}); ok {
+					server = strings.ReplaceAll(server, "{"+name+"}", stringField(v, "default"))
+				}
+			}
+		}
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %w", server, err)
+	}
+	return strings.TrimRight(ref.ResolveReference(serverURL).String(), "/"), nil
+}
+
+// endpoints converts every path and operation
+func (s *spec) endpoints(base, specURL string) []types.Endpoint {
+	paths, _ := s.doc["paths"].(map[string]interface{})
+	templates := make([]string, 0, len(paths))
+	for template := range paths {
+		if strings.HasPrefix(template, "/") {
+			templates = append(templates, template)
+		}
+	}
+	sort.Strings(templates)
+
+	var endpoints []types.Endpoint
+	for _, template := range templates {
+		item, ok := s.resolve(paths[template]).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, method := range specMethods {
+			op, ok := s.resolve(item[method]).(map[string]interface{})
+			if !ok {
+				continue
+			}
+			endpoints = append(endpoints, s.operation(base, template, method, item, op, specURL))
+		}
+	}
+	return endpoints
+}
+
+// operation builds endpoint of operation, parameters, request body and security go to metadata
+func (s *spec) operation(base, template, method string, item, op map[string]interface{}, specURL string) types.Endpoint {
+	metadata := map[string]interface{}{
+		"spec":         specURL,
+		"spec_version": s.version,
+		"path":         template,
+	}
+	if id := stringField(op, "operationId"); id != "" {
+		metadata["operation_id"] = id
+	}
+	if summary := stringField(op, "summary"); summary != "" {
+		metadata["summary"] = summary
+	}
+	if tags := stringList(op["tags"]); len(tags) > 0 {
+		metadata["tags"] = tags
+	}
+	if deprecated, _ := op["deprecated"].(bool); deprecated {
+		metadata["deprecated"] = true
+	}
+
+	params, examples, body := s.parameters(item, op)
+	if body == nil {
+		if body = s.requestBody(op); body != nil {
+			params = append(params, bodyParams(body)...)
+		}
+	}
+	if body != nil {
+		metadata["request_body"] = body
+	}
+	if len(params) > 0 {
+		metadata["params"] = params
+	}
+	if security, schemes := s.security(op); len(security) > 0 {
+		metadata["security"] = security
+		if len(schemes) > 0 {
+			metadata["security_schemes"] = schemes
+		}
+	}
+
+	// path parameters with example or default make URL requestable, others stay as {name}
+	path := template
+	for name, value := range examples {
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(value))
+	}
+
+	return types.Endpoint{
+		URL:      base + "/" + strings.TrimPrefix(path, "/"),
+		Method:   strings.ToUpper(method),
+		Source:   "openapi",
+		Depth:    1,
+		Metadata: metadata,
+	}
+}
+
+// parameters merges path item and operation parameters, operation ones override by name and location,
+// returns path parameter examples and Swagger 2 body or form request body
+func (s *spec) parameters(item, op map[string]interface{}) ([]types.Parameter, map[string]string, map[string]interface{}) {
+	var order []string
+	declared := make(map[string]map[string]interface{})
+	for _, list := range []interface{}{item["parameters"], op["parameters"]} {
+		entries, _ := list.([]interface{})
+		for _, entry := range entries {
+			p, ok := s.resolve(entry).(map[string]interface{})
+			if !ok || stringField(p, "name") == "" {
+				continue
+			}
+			key := stringField(p, "in") + " " + stringField(p, "name")
+			if _, ok := declared[key]; !ok {
+				order = append(order, key)
+			}
+			declared[key] = p
+		}
+	}
+
+	var params []types.Parameter
+	examples := make(map[string]string)
+	var body map[string]interface{}
+	form := map[string]interface{}{}
+	var formRequired []interface{}
+	var bodyFields []types.Parameter
+
+	for _, key := range order {
+		p := declared[key]
+		name := stringField(p, "name")
+		required, _ := p["required"].(bool)
+		schema, _ := s.resolve(p["schema"]).(map[string]interface{})
+
+		switch in := stringField(p, "in"); in {
+		case "body":
+			body = map[string]interface{}{
+				"required": required,
+				"content":  s.contentTypes(op, "application/json", s.resolveAll(p["schema"])),
+			}
+			bodyFields = bodyParams(body)
+			continue
+		case "formData":
+			prop := map[string]interface{}{"type": stringField(p, "type")}
+			form[name] = prop
+			if required {
+				formRequired = append(formRequired, name)
+			}
+		case "path":
+			if example := paramExample(p, schema); example != "" {
+				examples[name] = example
+			}
+		}
+
+		typ := stringField(p, "type")
+		if schema != nil {
+			typ = stringField(schema, "type")
+		}
+		params = append(params, types.Parameter{
+			Name:     name,
+			Location: paramLocation(stringField(p, "in")),
+			Required: required,
+			Type:     typ,
+		})
+	}
+
+	if body == nil && len(form) > 0 {
+		schema := map[string]interface{}{"type": "object", "properties": form}
+		if len(formRequired) > 0 {
+			schema["required"] = formRequired
+		}
+		body = map[string]interface{}{
+			"required": len(formRequired) > 0,
+			"content":  s.contentTypes(op, "application/x-www-form-urlencoded", schema),
+		}
+	}
+	return append(params, bodyFields...), examples, body
+}
+
+// contentTypes maps consumes of Swagger 2 operation or document to schema
+func (s *spec) contentTypes(op map[string]interface{}, fallback string, schema interface{}) map[string]interface{} {
+	consumes := stringList(op["consumes"])
+	if len(consumes) == 0 {
+		consumes = stringList(s.doc["consumes"])
+	}
+	if len(consumes) == 0 {
+		consumes = []string{fallback}
+	}
+
+	content := make(map[string]interface{}, len(consumes))
+	for _, ct := range consumes {
+		content[ct] = schema
+	}
+	return content
+}
+
+// requestBody returns OpenAPI 3 request body with content type schemas resolved
+func (s *spec) requestBody(op map[string]interface{}) map[string]interface{} {
+	rb, ok := s.resolve(op["requestBody"]).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	media, _ := rb["content"].(map[string]interface{})
+
+	content := make(map[string]interface{}, len(media))
+	for ct, m := range media {
+		m, _ := m.(map[string]interface{})
+		content[ct] = s.resolveAll(m["schema"])
+	}
+	required, _ := rb["required"].(bool)
+	return map[string]interface{}{
+		"required": required,
+		"content":  content,
+	}
+}
+
+// security returns requirements of operation, document ones if operation has none,
+// and definitions of schemes they name
+func (s *spec) security(op map[string]interface{}) ([]map[string][]string, map[string]interface{}) {
+	list, ok := op["security"].([]interface{})
+	if !ok {
+		list, _ = s.doc["security"].([]interface{})
+	}
+
+	var defs map[string]interface{}
+	if s.swagger {
+		defs, _ = s.doc["securityDefinitions"].(map[string]interface{})
+	} else {
+		components, _ := s.doc["components"].(map[string]interface{})
+		defs, _ = components["securitySchemes"].(map[string]interface{})
+	}
+
+	var requirements []map[string][]string
+	schemes := make(map[string]interface{})
+	for _, entry := range list {
+		req, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		requirement := make(map[string][]string, len(req))
+		for name, scopes := range req {
+			requirement[name] = stringList(scopes)
+			if def, ok := defs[name]; ok {
+				schemes[name] = s.resolve(def)
+			}
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, schemes
+}
+
+// resolve follows local $ref of node, unresolvable refs return node as is
+func (s *spec) resolve(node interface{}) interface{} {
+	for i := 0; i < maxRefDepth; i++ {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return node
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return node
+		}
+		target, ok := s.pointer(ref)
+		if !ok {
+			return node
+		}
+		node = target
+	}
+	return node
+}
+
+// resolveAll copies node with local $refs expanded, ref already expanded in same schema, cyclic,
+// deep or past node budget stays as {"$ref": ...} so hostile specs cannot blow up output
+func (s *spec) resolveAll(node interface{}) interface{} {
+	return s.expand(node, 0, make(map[string]bool))
+}
+
+// expand copies node, used holds refs already expanded in current schema
+func (s *spec) expand(node interface{}, depth int, used map[string]bool) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			if used[ref] || s.expanding[ref] || depth >= maxRefDepth || s.budget <= 0 {
+				return map[string]interface{}{"$ref": ref}
+			}
+			used[ref] = true
+			return s.expandRef(ref, depth+1)
+		}
+		s.budget--
+		// sorted keys decide deterministically which repeated ref is expanded
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		out := make(map[string]interface{}, len(v))
+		for _, k := range keys {
+			out[k] = s.expand(v[k], depth, used)
+		}
+		return out
+	case []interface{}:
+		s.budget--
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = s.expand(item, depth, used)
+		}
+		return out
+	}
+	return node
+}
+
+// expandRef expands target of ref once per depth, result is shared by every schema using it
+func (s *spec) expandRef(ref string, depth int) interface{} {
+	key := fmt.Sprintf("%d %s", depth, ref)
+	if out, ok := s.expanded[key]; ok {
+		return out
+	}
+
+	target, ok := s.pointer(ref)
+	if !ok {
+		return map[string]interface{}{"$ref": ref}
+	}
+
+	s.expanding[ref] = true
+	out := s.expand(target, depth, map[string]bool{ref: true})
+	delete(s.expanding, ref)
+
+	s.expanded[key] = out
+	return out
+}
+
+// pointer looks up local JSON pointer like #/components/schemas/Pet
+func (s *spec) pointer(ref string) (interface{}, bool) {
+	path, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, false
+	}
+
+	var node interface{} = s.doc
+	for _, token := range strings.Split(path, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if node, ok = m[token]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// bodyParams top-level properties of JSON or form request body schema
+func bodyParams(body map[string]interface{}) []types.Parameter {
+	content, _ := body["content"].(map[string]interface{})
+	cts := make([]string, 0, len(content))
+	for ct := range content {
+		cts = append(cts, ct)
+	}
+	sort.Strings(cts)
+
+	for _, ct := range cts {
+		location := ""
+		switch {
+		case strings.Contains(ct, "json"):
+			location = types.ParamJSON
+		case strings.Contains(ct, "x-www-form-urlencoded"), strings.Contains(ct, "multipart/form-data"):
+			location = types.ParamForm
+		default:
+			continue
+		}
+
+		schema, _ := content[ct].(map[string]interface{})
+		props, _ := schema["properties"].(map[string]interface{})
+		if len(props) == 0 {
+			continue
+		}
+		required := stringList(schema["required"])
+
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		params := make([]types.Parameter, 0, len(names))
+		for _, name := range names {
+			prop, _ := props[name].(map[string]interface{})
+			params = append(params, types.Parameter{
+				Name:     name,
+				Location: location,
+				Required: containsFold(required, name),
+				Type:     stringField(prop, "type"),
+			})
+		}
+		return params
+	}
+	return nil
+}
+
+// paramLocation maps spec parameter location to types.Param* constant
+func paramLocation(in string) string {
+	switch in {
+	case "query":
+		return types.ParamQuery
+	case "path":
+		return types.ParamPath
+	case "header":
+		return types.ParamHeader
+	case "cookie":
+		return types.ParamCookie
+	case "formData":
+		return types.ParamForm
+	}
+	return in
+}
+
+// paramExample returns example or default value of parameter
+func paramExample(p, schema map[string]interface{}) string {
+	for _, m := range []map[string]interface{}{p, schema} {
+		for _, key := range []string{"example", "default"} {
+			switch v := m[key].(type) {
+			case string:
+				return v
+			case float64, bool:
+				return fmt.Sprint(v)
+			}
+		}
+	}
+	return ""
+}
+
+// stringField returns field of map as string, numbers like unquoted YAML versions are formatted
+func stringField(m map[string]interface{}, key string) string {
+	switch v := m[key].(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// stringList returns string items of list
+func stringList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// containsFold checks if list contains s ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
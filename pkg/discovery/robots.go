@@ -0,0 +1,319 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// userAgentToken name matched against robots.txt User-agent lines
+const userAgentToken = "gobrutescanner"
+
+// sitemap limits, index files may nest and point to many sitemaps
+const (
+	maxSitemaps     = 50
+	maxSitemapDepth = 3
+	maxSitemapSize  = 50 << 20
+)
+
+// WithSeeds seeds crawl with paths of robots.txt and URLs of sitemaps
+func WithSeeds(enabled bool) Option {
+	return func(c *Crawler) {
+		c.seeds = enabled
+	}
+}
+
+// WithHonorRobots skips pages robots.txt disallows for crawler
+func WithHonorRobots(enabled bool) Option {
+	return func(c *Crawler) {
+		c.honorRobots = enabled
+	}
+}
+
+// robots parsed robots.txt
+type robots struct {
+	groups   []robotsGroup
+	sitemaps []string
+}
+
+// robotsGroup rules of user agents
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+}
+
+// robotsRule Allow or Disallow line, path may use * and $
+type robotsRule struct {
+	allow bool
+	path  string
+	re    *regexp.Regexp
+}
+
+// parseRobots parses robots.txt, unknown lines are ignored
+func parseRobots(body string) *robots {
+	r := &robots{}
+	var group *robotsGroup
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if group == nil || len(group.rules) > 0 {
+				r.groups = append(r.groups, robotsGroup{})
+				group = &r.groups[len(r.groups)-1]
+			}
+			group.agents = append(group.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			// empty Disallow allows everything
+			if group == nil || value == "" {
+				continue
+			}
+			re, err := robotsPattern(value)
+			if err != nil {
+				continue
+			}
+			group.rules = append(group.rules, robotsRule{allow: key == "allow", path: value, re: re})
+		case "sitemap":
+			if value != "" {
+				r.sitemaps = append(r.sitemaps, value)
+			}
+		}
+	}
+
+	return r
+}
+
+// rules returns rules of group naming agent, "*" group otherwise, empty agent names are ignored
+func (r *robots) rules(agent string) []robotsRule {
+	var fallback []robotsRule
+	for _, group := range r.groups {
+		for _, name := range group.agents {
+			switch {
+			case name == "":
+			case name == "*":
+				fallback = append(fallback, group.rules...)
+			case strings.Contains(agent, name):
+				return group.rules
+			}
+		}
+	}
+	return fallback
+}
+
+// allowed checks path with query against longest matching rule, Allow wins ties, nil robots allow all
+func (r *robots) allowed(agent, path string) bool {
+	if r == nil {
+		return true
+	}
+
+	allow, length := true, -1
+	for _, rule := range r.rules(agent) {
+		if !rule.re.MatchString(path) {
+			continue
+		}
+		if len(rule.path) > length || len(rule.path) == length && rule.allow {
+			allow, length = rule.allow, len(rule.path)
+		}
+	}
+	return allow
+}
+
+// robotsPattern compiles rule path with * wildcard and $ end anchor
+func robotsPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.HasSuffix(pattern, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(pattern, "$")), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+
+	return regexp.Compile(expr)
+}
+
+// robotsAllowed checks URL against robots.txt when crawler honors it
+func (c *Crawler) robotsAllowed(rawURL string) bool {
+	if !c.honorRobots {
+		return true
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return c.robots.allowed(userAgentToken, path)
+}
+
+// seed loads robots.txt and sitemaps, records their endpoints and returns in-scope URLs to crawl
+func (c *Crawler) seed(ctx context.Context) []string {
+	c.robots = nil
+	robotsURL := c.baseURL.ResolveReference(&url.URL{Path: "/robots.txt"}).String()
+	if c.active.Allows(robotsURL) {
+		if body, status, err := c.get(ctx, robotsURL, "text/plain,*/*"); err == nil && status == http.StatusOK {
+			c.robots = parseRobots(string(body))
+		}
+	}
+	if !c.seeds {
+		return nil
+	}
+
+	var seeds []string
+	add := func(endpoint types.Endpoint) {
+		endpoint, ok := c.scoped(endpoint)
+		if ok && c.robotsAllowed(endpoint.URL) {
+			seeds = append(seeds, endpoint.URL)
+		}
+
+		c.mu.Lock()
+		c.endpoints = append(c.endpoints, endpoint)
+		c.mu.Unlock()
+	}
+
+	sitemaps := []string{c.baseURL.ResolveReference(&url.URL{Path: "/sitemap.xml"}).String()}
+	if c.robots != nil {
+		seen := make(map[string]bool)
+		for _, group := range c.robots.groups {
+			for _, rule := range group.rules {
+				path := strings.TrimSuffix(rule.path, "$")
+				path, _, _ = strings.Cut(path, "*")
+				if path == "" || path == "/" || seen[path] {
+					continue
+				}
+				seen[path] = true
+
+				ruleType := "disallow"
+				if rule.allow {
+					ruleType = "allow"
+				}
+				path, query, _ := strings.Cut(path, "?")
+				add(types.Endpoint{
+					URL:    c.baseURL.ResolveReference(&url.URL{Path: path, RawQuery: query}).String(),
+					Method: "GET",
+					Source: "robots",
+					Depth:  1,
+					Metadata: map[string]interface{}{
+						"rule": ruleType,
+					},
+				})
+			}
+		}
+		if len(c.robots.sitemaps) > 0 {
+			sitemaps = c.robots.sitemaps
+		}
+	}
+
+	fetched := 0
+	c.walkSitemaps(ctx, sitemaps, 0, &fetched, add)
+	return seeds
+}
+
+// sitemapXML urlset or sitemapindex document
+type sitemapXML struct {
+	URLs     []sitemapEntry `xml:"url"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+// sitemapEntry url or sitemap element
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// walkSitemaps fetches sitemaps and nested index entries, adds page URLs
+func (c *Crawler) walkSitemaps(ctx context.Context, sitemaps []string, depth int, fetched *int, add func(types.Endpoint)) {
+	for _, sitemapURL := range sitemaps {
+		if ctx.Err() != nil || *fetched >= maxSitemaps || depth >= maxSitemapDepth {
+			return
+		}
+		if !c.active.Allows(sitemapURL) {
+			continue
+		}
+		*fetched++
+
+		body, status, err := c.get(ctx, sitemapURL, "application/xml,text/xml,*/*")
+		if err != nil || status != http.StatusOK {
+			continue
+		}
+		body, err = gunzip(body)
+		if err != nil {
+			continue
+		}
+
+		var doc sitemapXML
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			// plain text sitemap, one URL per line
+			for _, line := range strings.Split(string(body), "\n") {
+				if line = strings.TrimSpace(line); strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
+					add(sitemapEndpoint(line, "", sitemapURL))
+				}
+			}
+			continue
+		}
+
+		for _, entry := range doc.URLs {
+			if loc := strings.TrimSpace(entry.Loc); loc != "" {
+				add(sitemapEndpoint(loc, entry.LastMod, sitemapURL))
+			}
+		}
+
+		var nested []string
+		for _, entry := range doc.Sitemaps {
+			if loc := strings.TrimSpace(entry.Loc); loc != "" {
+				nested = append(nested, loc)
+			}
+		}
+		c.walkSitemaps(ctx, nested, depth+1, fetched, add)
+	}
+}
+
+// sitemapEndpoint endpoint of sitemap URL
+func sitemapEndpoint(loc, lastMod, sitemapURL string) types.Endpoint {
+	metadata := map[string]interface{}{"sitemap": sitemapURL}
+	if lastMod != "" {
+		metadata["lastmod"] = strings.TrimSpace(lastMod)
+	}
+	return types.Endpoint{
+		URL:      loc,
+		Method:   "GET",
+		Source:   "sitemap",
+		Depth:    1,
+		Metadata: metadata,
+	}
+}
+
+// gunzip inflates gzip data, other data is returned as is
+func gunzip(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return data, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(io.LimitReader(r, maxSitemapSize))
+}
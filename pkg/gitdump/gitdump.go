@@ -0,0 +1,447 @@
+package gitdump
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// maxObjectSize limits size of fetched git file or object
+const maxObjectSize = 32 << 20
+
+// maxCommits limits commits walked per repository
+const maxCommits = 200
+
+// commonRefs refs tried besides HEAD target and packed-refs
+var commonRefs = []string{
+	"refs/heads/master",
+	"refs/heads/main",
+	"refs/heads/develop",
+	"refs/remotes/origin/HEAD",
+	"refs/remotes/origin/master",
+	"refs/remotes/origin/main",
+	"refs/stash",
+	"ORIG_HEAD",
+	"FETCH_HEAD",
+}
+
+var hashRe = regexp.MustCompile(`\b[0-9a-f]{40}\b`)
+
+// File tracked file of repository
+type File struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+	Mode uint32 `json:"mode"`
+	// Dumped is true if blob was written to output directory
+	Dumped bool `json:"dumped,omitempty"`
+}
+
+// Repository reconstructed from exposed .git directory
+type Repository struct {
+	URL   string            `json:"url"`
+	Head  string            `json:"head"`
+	Refs  map[string]string `json:"refs,omitempty"`
+	Files []File            `json:"files"`
+	// Packs pack files listed in objects/info/packs, their objects are not read
+	Packs []string `json:"packs,omitempty"`
+}
+
+// Dumper reconstructs repositories over HTTP
+type Dumper struct {
+	client  types.HTTPClient
+	workers int
+}
+
+// New creates dumper, workers fetch blobs in parallel
+func New(client types.HTTPClient, workers int) *Dumper {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Dumper{client: client, workers: workers}
+}
+
+// Dump reads HEAD, refs, packed-refs and index of .git directory at gitURL and lists tracked files,
+// files missing in index are found by walking trees of loose commit objects,
+// non-empty outDir gets blobs of loose objects written under their paths
+func (d *Dumper) Dump(ctx context.Context, gitURL, outDir string) (*Repository, error) {
+	gitURL = strings.TrimRight(gitURL, "/") + "/"
+	repo := &Repository{URL: gitURL, Refs: make(map[string]string)}
+
+	head, err := d.fetch(ctx, gitURL+"HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch HEAD: %w", err)
+	}
+	repo.Head = strings.TrimSpace(string(head))
+	if !strings.HasPrefix(repo.Head, "ref: ") && !hashRe.MatchString(repo.Head) {
+		return nil, errors.New("HEAD is not a git HEAD file")
+	}
+
+	d.readRefs(ctx, repo)
+
+	files := make(map[string]File)
+	if index, err := d.fetch(ctx, gitURL+"index"); err == nil {
+		entries, err := parseIndex(index)
+		if err == nil {
+			for _, f := range entries {
+				files[f.Path] = f
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	commits := 0
+	for _, hash := range repo.commits() {
+		d.walkCommit(ctx, gitURL, hash, files, seen, &commits)
+	}
+
+	if packs, err := d.fetch(ctx, gitURL+"objects/info/packs"); err == nil {
+		for _, line := range strings.Split(string(packs), "\n") {
+			if name, ok := strings.CutPrefix(strings.TrimSpace(line), "P "); ok {
+				repo.Packs = append(repo.Packs, name)
+			}
+		}
+	}
+
+	repo.Files = make([]File, 0, len(files))
+	for _, f := range files {
+		repo.Files = append(repo.Files, f)
+	}
+	sort.Slice(repo.Files, func(i, j int) bool {
+		return repo.Files[i].Path < repo.Files[j].Path
+	})
+
+	if outDir != "" {
+		if err := d.dumpBlobs(ctx, gitURL, outDir, repo.Files); err != nil {
+			return repo, err
+		}
+	}
+
+	return repo, ctx.Err()
+}
+
+// readRefs resolves HEAD and reads known refs
+func (d *Dumper) readRefs(ctx context.Context, repo *Repository) {
+	names := append([]string(nil), commonRefs...)
+	if ref, ok := strings.CutPrefix(repo.Head, "ref: "); ok {
+		names = append([]string{ref}, names...)
+	} else {
+		repo.Refs["HEAD"] = repo.Head
+	}
+
+	if packed, err := d.fetch(ctx, repo.URL+"packed-refs"); err == nil {
+		for _, line := range strings.Split(string(packed), "\n") {
+			hash, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+			if ok && hashRe.MatchString(hash) {
+				repo.Refs[name] = hash
+			}
+		}
+	}
+
+	for _, name := range names {
+		if _, ok := repo.Refs[name]; ok {
+			continue
+		}
+		data, err := d.fetch(ctx, repo.URL+name)
+		if err != nil {
+			continue
+		}
+		if hash := hashRe.FindString(string(data)); hash != "" {
+			repo.Refs[name] = hash
+		}
+	}
+
+	// reflog holds hashes of older commits too
+	if logs, err := d.fetch(ctx, repo.URL+"logs/HEAD"); err == nil {
+		for i, hash := range hashRe.FindAllString(string(logs), -1) {
+			repo.Refs[fmt.Sprintf("logs/HEAD@%d", i)] = hash
+		}
+	}
+}
+
+// commits returns unique ref hashes
+func (r *Repository) commits() []string {
+	seen := make(map[string]bool)
+	var hashes []string
+	for _, hash := range r.Refs {
+		if !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+// walkCommit adds files of commit trees that are not known yet, parents are followed up to maxCommits
+func (d *Dumper) walkCommit(ctx context.Context, gitURL, hash string, files map[string]File, seen map[string]bool, commits *int) {
+	queue := []string{hash}
+	for len(queue) > 0 && *commits < maxCommits && ctx.Err() == nil {
+		hash, queue = queue[0], queue[1:]
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		kind, data, err := d.object(ctx, gitURL, hash)
+		if err != nil || kind != "commit" {
+			continue
+		}
+		*commits++
+
+		for _, line := range strings.Split(string(data), "\n") {
+			if line == "" {
+				break
+			}
+			if tree, ok := strings.CutPrefix(line, "tree "); ok {
+				d.walkTree(ctx, gitURL, tree, "", files, seen)
+			}
+			if parent, ok := strings.CutPrefix(line, "parent "); ok {
+				queue = append(queue, parent)
+			}
+		}
+	}
+}
+
+// walkTree adds blobs of tree under prefix
+func (d *Dumper) walkTree(ctx context.Context, gitURL, hash, prefix string, files map[string]File, seen map[string]bool) {
+	if seen[hash] || ctx.Err() != nil {
+		return
+	}
+	seen[hash] = true
+
+	kind, data, err := d.object(ctx, gitURL, hash)
+	if err != nil || kind != "tree" {
+		return
+	}
+
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if space < 0 || nul < space || len(data) < nul+21 {
+			return
+		}
+
+		var mode uint32
+		fmt.Sscanf(string(data[:space]), "%o", &mode)
+		name := string(data[space+1 : nul])
+		child := hex.EncodeToString(data[nul+1 : nul+21])
+		data = data[nul+21:]
+
+		switch {
+		case mode == 0o40000 && LocalPath(prefix+name):
+			d.walkTree(ctx, gitURL, child, prefix+name+"/", files, seen)
+		case mode == 0o160000, !LocalPath(prefix + name):
+			// submodule commit lives in other repository, names escaping repository are skipped
+		default:
+			if _, ok := files[prefix+name]; !ok {
+				files[prefix+name] = File{Path: prefix + name, Hash: child, Mode: mode}
+			}
+		}
+	}
+}
+
+// LocalPath checks slash separated path stays under directory it is joined to, ".." segments are refused
+func LocalPath(p string) bool {
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." {
+			return false
+		}
+	}
+	return filepath.IsLocal(filepath.FromSlash(p))
+}
+
+// dumpBlobs writes blobs of files under outDir
+func (d *Dumper) dumpBlobs(ctx context.Context, gitURL, outDir string, files []File) error {
+	jobs := make(chan int, len(files))
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+
+	for i := 0; i < d.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if ctx.Err() != nil {
+					return
+				}
+
+				err := d.dumpBlob(ctx, gitURL, outDir, files[j])
+				mu.Lock()
+				if err == nil {
+					files[j].Dumped = true
+				} else if firstErr == nil && !errors.Is(err, errNotFound) {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// dumpBlob writes single blob, paths escaping outDir are refused
+func (d *Dumper) dumpBlob(ctx context.Context, gitURL, outDir string, f File) error {
+	if !LocalPath(f.Path) {
+		return fmt.Errorf("unsafe path %q", f.Path)
+	}
+
+	kind, data, err := d.object(ctx, gitURL, f.Hash)
+	if err != nil {
+		return err
+	}
+	if kind != "blob" {
+		return fmt.Errorf("object %s of %s is %s", f.Hash, f.Path, kind)
+	}
+
+	target := filepath.Join(outDir, filepath.FromSlash(f.Path))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(target, data, 0o644)
+}
+
+// object fetches and inflates loose object, returns type and content
+func (d *Dumper) object(ctx context.Context, gitURL, hash string) (string, []byte, error) {
+	if len(hash) != 40 {
+		return "", nil, fmt.Errorf("invalid object hash %q", hash)
+	}
+
+	raw, err := d.fetch(ctx, gitURL+"objects/"+hash[:2]+"/"+hash[2:])
+	if err != nil {
+		return "", nil, err
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return "", nil, fmt.Errorf("object %s: %w", hash, err)
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(io.LimitReader(zr, maxObjectSize))
+	if err != nil {
+		return "", nil, fmt.Errorf("object %s: %w", hash, err)
+	}
+
+	header, content, ok := bytes.Cut(data, []byte{0})
+	if !ok {
+		return "", nil, fmt.Errorf("object %s: missing header", hash)
+	}
+	kind, _, _ := strings.Cut(string(header), " ")
+	return kind, content, nil
+}
+
+// errNotFound file is missing on server
+var errNotFound = errors.New("not found")
+
+// fetch downloads file of .git directory
+func (d *Dumper) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "GoBruteScanner/1.0")
+	req.Header.Set("Accept", "*/*")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %w (status %d)", url, errNotFound, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxObjectSize))
+}
+
+// parseIndex reads paths and blob hashes of index versions 2, 3 and 4
+func parseIndex(data []byte) ([]File, error) {
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		return nil, errors.New("not a git index")
+	}
+
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("unsupported index version %d", version)
+	}
+	// count comes from target, every entry takes at least 62 bytes
+	count := binary.BigEndian.Uint32(data[8:12])
+	if limit := uint32(len(data) / 62); count > limit {
+		count = limit
+	}
+
+	files := make([]File, 0, count)
+	pos := 12
+	prev := ""
+
+	for i := uint32(0); i < count; i++ {
+		start := pos
+		if len(data) < pos+62 {
+			return files, errors.New("truncated index")
+		}
+
+		mode := binary.BigEndian.Uint32(data[pos+24 : pos+28])
+		hash := hex.EncodeToString(data[pos+40 : pos+60])
+		flags := binary.BigEndian.Uint16(data[pos+60 : pos+62])
+		pos += 62
+		if version >= 3 && flags&0x4000 != 0 {
+			pos += 2
+		}
+		if pos > len(data) {
+			return files, errors.New("truncated index")
+		}
+
+		var name string
+		if version == 4 {
+			strip, n := binary.Uvarint(data[pos:])
+			if n <= 0 || int(strip) > len(prev) {
+				return files, errors.New("corrupt index path")
+			}
+			pos += n
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return files, errors.New("truncated index")
+			}
+			name = prev[:len(prev)-int(strip)] + string(data[pos:pos+end])
+			pos += end + 1
+		} else {
+			end := bytes.IndexByte(data[pos:], 0)
+			if end < 0 {
+				return files, errors.New("truncated index")
+			}
+			name = string(data[pos : pos+end])
+			// entries are NUL padded to multiple of 8 bytes
+			pos = start + (pos+end-start+8)/8*8
+		}
+		prev = name
+
+		if mode&0o170000 == 0o160000 || !LocalPath(name) {
+			continue
+		}
+		files = append(files, File{Path: name, Hash: hash, Mode: mode})
+	}
+
+	return files, nil
+}
@@ -0,0 +1,66 @@
+package httpclient
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// defaultAPIKeyHeader header for apikey auth without Header
+const defaultAPIKeyHeader = "X-API-Key"
+
+// SetAuth changes credentials attached to every request, nil disables auth
+func (c *Client) SetAuth(auth *types.AuthConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if auth != nil {
+		copied := *auth
+		auth = &copied
+	}
+	c.config.Auth = auth
+}
+
+// GetAuth returns current credentials
+func (c *Client) GetAuth() *types.AuthConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.config.Auth == nil {
+		return nil
+	}
+	copied := *c.config.Auth
+	return &copied
+}
+
+// authorize attaches credentials unless request already has them
+func (c *Client) authorize(req *http.Request) {
+	auth := c.GetAuth()
+	if auth == nil {
+		return
+	}
+
+	switch strings.ToLower(auth.Type) {
+	case types.AuthBasic:
+		if req.Header.Get("Authorization") == "" {
+			req.SetBasicAuth(auth.Username, auth.Password)
+		}
+	case types.AuthBearer:
+		if req.Header.Get("Authorization") == "" && auth.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+auth.Token)
+		}
+	case types.AuthAPIKey:
+		header := auth.Header
+		if header == "" {
+			header = defaultAPIKeyHeader
+		}
+		if req.Header.Get(header) == "" && auth.APIKey != "" {
+			req.Header.Set(header, auth.APIKey)
+		}
+	case types.AuthHeader:
+		if auth.Header != "" && req.Header.Get(auth.Header) == "" {
+			req.Header.Set(auth.Header, auth.Token)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package httpclient
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultHostRate start rate for throttled host when global limit is disabled
+	defaultHostRate = 10.0
+	// minHostRate lowest rate host can be slowed down to
+	minHostRate = 0.2
+	// recoverAfter successful responses needed to speed host up again
+	recoverAfter = 20
+)
+
+// hostState throttling state of single host
+type hostState struct {
+	limiter   *limiter
+	until     time.Time
+	successes int
+}
+
+// throttler slows down hosts that answer with 429/503
+type throttler struct {
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// newThrottler creates throttler
+func newThrottler() *throttler {
+	return &throttler{
+		hosts: make(map[string]*hostState),
+	}
+}
+
+// wait blocks while host is paused and until host limiter allows request
+func (t *throttler) wait(ctx context.Context, host string) error {
+	t.mu.Lock()
+	st, ok := t.hosts[host]
+	if !ok {
+		t.mu.Unlock()
+		return nil
+	}
+	pause := time.Until(st.until)
+	l := st.limiter
+	t.mu.Unlock()
+
+	if err := sleep(ctx, pause); err != nil {
+		return err
+	}
+	return l.wait(ctx)
+}
+
+// slowdown halves host rate, global is client rate limit
+func (t *throttler) slowdown(host string, global float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, ok := t.hosts[host]
+	if !ok {
+		rate := global
+		if rate <= 0 {
+			rate = defaultHostRate
+		}
+		st = &hostState{limiter: newLimiter(rate, 1)}
+		t.hosts[host] = st
+	}
+
+	rate := st.limiter.limit() / 2
+	if rate < minHostRate {
+		rate = minHostRate
+	}
+	st.limiter.set(rate, 1)
+	st.successes = 0
+}
+
+// pause stops all requests to host for d
+func (t *throttler) pause(host string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if st, ok := t.hosts[host]; ok {
+		if until := time.Now().Add(d); until.After(st.until) {
+			st.until = until
+		}
+	}
+}
+
+// success speeds host up after enough successful responses
+func (t *throttler) success(host string, global float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, ok := t.hosts[host]
+	if !ok {
+		return
+	}
+
+	st.successes++
+	if st.successes < recoverAfter {
+		return
+	}
+	st.successes = 0
+
+	ceiling := global
+	if ceiling <= 0 {
+		ceiling = defaultHostRate
+	}
+
+	rate := st.limiter.limit() * 1.5
+	if rate >= ceiling {
+		delete(t.hosts, host)
+		return
+	}
+	st.limiter.set(rate, 1)
+}
+
+// isThrottled checks if status code means server asks to slow down
+func isThrottled(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// retryAfter parses Retry-After header in seconds or HTTP-date form
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		d := time.Until(date)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+// backoff returns exponential delay with jitter for attempt, max <= 0 means no cap
+func backoff(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+
+	d := base
+	// without cap doubling stops short of overflow
+	for i := 0; i < attempt && (max <= 0 || d < max) && d <= math.MaxInt64/2; i++ {
+		d *= 2
+	}
+	if max > 0 && d > max {
+		d = max
+	}
+
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
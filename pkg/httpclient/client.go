@@ -1,11 +1,16 @@
 package httpclient
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
@@ -14,31 +19,54 @@ import (
 // Client implements HTTP Client interface
 type Client struct {
 	client       *http.Client
+	transport    *sniTransport
 	config       types.Config
 	proxies      []*url.URL
 	currentProxy int
+	limiter      *limiter
+	throttler    *throttler
+	session      *session
+	jar          *Jar
+	seeded       bool
+	requests     atomic.Int64
+	retries      atomic.Int64
+	throttled    atomic.Int64
+	slowdowns    atomic.Int64
 	mu           sync.Mutex
 }
 
+// Stats client counters
+type Stats struct {
+	Requests  int
+	Retries   int
+	Throttled int
+	Slowdowns int
+}
+
 // New creates client
 func New(config types.Config) (*Client, error) {
-	transport := &http.Transport{
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := newSNITransport(&http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: config.InsecureSSL,
 		},
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 10,
 		IdleConnTimeout:     30 * time.Second,
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
+		DialContext:         dialer.DialContext,
 		TLSHandshakeTimeout: 10 * time.Second,
-	}
+	}, dialer)
+
+	jar := NewJar()
 
 	httpClient := &http.Client{
 		Transport: transport,
 		Timeout:   config.Timeout,
+		Jar:       jar,
 	}
 
 	if config.MaxRedirects >= 0 {
@@ -50,9 +78,40 @@ func New(config types.Config) (*Client, error) {
 		}
 	}
 
+	var sess *session
+	if config.Login != nil {
+		login := *config.Login
+		if base, err := url.Parse(config.BaseURL); err == nil && config.BaseURL != "" {
+			if ref, err := url.Parse(login.URL); err == nil {
+				login.URL = base.ResolveReference(ref).String()
+			}
+		}
+
+		var err error
+		sess, err = newSession(login)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	client := &Client{
-		client: httpClient,
-		config: config,
+		client:    httpClient,
+		transport: transport,
+		config:    config,
+		limiter:   newLimiter(float64(config.RateLimit), config.RateBurst),
+		throttler: newThrottler(),
+		session:   sess,
+		jar:       jar,
+	}
+
+	// static cookies go to jar so they are replaced by Set-Cookie like in browser
+	if base, err := url.Parse(config.BaseURL); err == nil && base.Host != "" && len(config.Cookies) > 0 {
+		cookies := make([]*http.Cookie, 0, len(config.Cookies))
+		for name, value := range config.Cookies {
+			cookies = append(cookies, &http.Cookie{Name: name, Value: value, Path: "/"})
+		}
+		jar.SetCookies(base, cookies)
+		client.seeded = true
 	}
 
 	if len(config.ProxyURLs) > 0 {
@@ -71,7 +130,7 @@ func New(config types.Config) (*Client, error) {
 	return client, nil
 }
 
-// Do makes req
+// Do makes req, blocks until rate limiter allows it and retries throttled responses
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	if req.Header.Get("User-Agent") == "" && c.config.UserAgent != "" {
 		req.Header.Set("User-Agent", c.config.UserAgent)
@@ -83,7 +142,16 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 		}
 	}
 
-	if len(c.config.Cookies) > 0 {
+	c.authorize(req)
+
+	mark := sessionMark(req.Context())
+	generation := 0
+	if c.session != nil && mark != markLogin {
+		generation = c.session.gen()
+		c.session.apply(req)
+	}
+
+	if len(c.config.Cookies) > 0 && !c.seeded {
 		for name, value := range c.config.Cookies {
 			req.AddCookie(&http.Cookie{
 				Name:  name,
@@ -92,22 +160,175 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 		}
 	}
 
-	resp, err := c.client.Do(req)
+	return c.do(req, mark, generation)
+}
+
+// do sends prepared req with rate limiting and retries
+func (c *Client) do(req *http.Request, mark, generation int) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+	policy := c.GetRetryPolicy()
+
+	for throttleAttempt, failures := 0, 0; ; {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		if err := c.throttler.wait(ctx, host); err != nil {
+			return nil, err
+		}
+
+		countAttempt(ctx)
+		c.requests.Add(1)
+		resp, err := c.send(req, policy.AttemptTimeout)
+
+		if err != nil && c.config.ProxyRotate && len(c.proxies) > 1 {
+			c.mu.Lock()
+			c.currentProxy = (c.currentProxy + 1) % len(c.proxies)
+			c.setProxy(c.proxies[c.currentProxy])
+			c.mu.Unlock()
+		}
+
+		if ctx.Err() != nil {
+			return resp, err
+		}
+
+		if (err != nil || !isThrottled(resp.StatusCode)) && failures+1 < policy.MaxAttempts &&
+			shouldRetry(policy, req, resp, err) {
+			next, rewindErr := rewind(req)
+			if rewindErr == nil {
+				if resp != nil {
+					drain(resp)
+				}
+				if err := sleep(ctx, backoff(failures, c.config.BackoffBase, c.config.BackoffMax)); err != nil {
+					return nil, err
+				}
+
+				failures++
+				c.retries.Add(1)
+				req = next
+				continue
+			}
+		}
+
+		if err != nil {
+			return resp, err
+		}
+
+		if !isThrottled(resp.StatusCode) {
+			c.throttler.success(host, c.limiter.limit())
+			if c.session != nil && mark == 0 && generation > 0 && c.session.expired(resp) {
+				return c.resend(req, resp, generation)
+			}
+			return resp, nil
+		}
+
+		c.throttled.Add(1)
+		c.throttler.slowdown(host, c.limiter.limit())
+		c.slowdowns.Add(1)
+
+		if throttleAttempt >= c.config.ThrottleRetries {
+			return resp, nil
+		}
+
+		delay, ok := retryAfter(resp)
+		if !ok {
+			delay = backoff(throttleAttempt, c.config.BackoffBase, c.config.BackoffMax)
+		}
+		if c.config.BackoffMax > 0 && delay > c.config.BackoffMax {
+			return resp, nil
+		}
+
+		next, err := rewind(req)
+		if err != nil {
+			return resp, nil
+		}
+		drain(resp)
+
+		c.throttler.pause(host, delay)
+		c.retries.Add(1)
+		throttleAttempt++
+		req = next
+	}
+}
+
+// resend logs in again and repeats request once with new session
+func (c *Client) resend(req *http.Request, resp *http.Response, generation int) (*http.Response, error) {
+	next, err := rewind(req)
+	if err != nil {
+		return resp, nil
+	}
+	drain(resp)
+
+	if err := c.relogin(req.Context(), generation); err != nil {
+		return nil, fmt.Errorf("re-login failed: %w", err)
+	}
+
+	c.session.strip(next)
+	c.session.apply(next)
+	return c.do(next, markResent, generation)
+}
+
+// send makes single attempt on copy of req, timeout <= 0 means no per-attempt timeout
+func (c *Client) send(req *http.Request, timeout time.Duration) (*http.Response, error) {
+	// http.Client adds jar cookies to the request it is given, copy keeps req clean for retries
+	if timeout <= 0 {
+		return c.client.Do(req.Clone(req.Context()))
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := c.client.Do(req.Clone(ctx))
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// Jar returns cookie jar shared by all requests
+func (c *Client) Jar() *Jar {
+	return c.jar
+}
+
+// SetRetryPolicy changes retry policy for transport errors
+func (c *Client) SetRetryPolicy(policy types.RetryPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config.Retry = policy
+}
 
-	if err != nil && c.config.ProxyRotate && len(c.proxies) > 1 {
-		c.mu.Lock()
-		c.currentProxy = (c.currentProxy + 1) % len(c.proxies)
-		c.setProxy(c.proxies[c.currentProxy])
-		c.mu.Unlock()
+// GetRetryPolicy returns current retry policy
+func (c *Client) GetRetryPolicy() types.RetryPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.config.Retry
+}
+
+// GetStats returns request, retry and throttling counters
+func (c *Client) GetStats() Stats {
+	return Stats{
+		Requests:  int(c.requests.Load()),
+		Retries:   int(c.retries.Load()),
+		Throttled: int(c.throttled.Load()),
+		Slowdowns: int(c.slowdowns.Load()),
 	}
+}
 
-	return resp, err
+// SetRateLimit changes requests per second and burst, rps <= 0 disables limiting
+func (c *Client) SetRateLimit(rps, burst int) {
+	c.limiter.set(float64(rps), burst)
+}
+
+// GetRateLimit returns current requests per second
+func (c *Client) GetRateLimit() int {
+	return int(c.limiter.limit())
 }
 
 // SetProxy sets proxy
 func (c *Client) SetProxy(proxyURL string) error {
 	if proxyURL == "" {
-		c.client.Transport.(*http.Transport).Proxy = nil
+		c.transport.setProxy(nil)
 		return nil
 	}
 
@@ -148,6 +369,30 @@ func (c *Client) GetProxy() string {
 }
 
 func (c *Client) setProxy(proxy *url.URL) error {
-	c.client.Transport.(*http.Transport).Proxy = http.ProxyURL(proxy)
+	c.transport.setProxy(http.ProxyURL(proxy))
 	return nil
 }
+
+// rewind returns copy of req that can be sent again
+func rewind(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("request body can not be replayed")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next.Body = body
+	return next, nil
+}
+
+// drain reads rest of body so connection can be reused
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+}
@@ -0,0 +1,354 @@
+package httpclient
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// httpOnlyPrefix marks HttpOnly cookies in Netscape cookie files
+const httpOnlyPrefix = "#HttpOnly_"
+
+// Jar cookie jar that keeps full cookie attributes so cookies can be inspected and exported
+type Jar struct {
+	mu      sync.Mutex
+	entries map[string]map[string]*http.Cookie
+	// hostOnly ids of cookies sent only to exact host
+	hostOnly map[string]bool
+}
+
+// NewJar creates empty jar
+func NewJar() *Jar {
+	return &Jar{
+		entries:  make(map[string]map[string]*http.Cookie),
+		hostOnly: make(map[string]bool),
+	}
+}
+
+// SetCookies stores cookies received from u
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host := canonicalHost(u)
+	if host == "" {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	for _, cookie := range cookies {
+		domain, hostOnly, ok := cookieDomain(host, cookie.Domain)
+		if !ok {
+			continue
+		}
+
+		path := cookie.Path
+		if path == "" || path[0] != '/' {
+			path = defaultPath(u.Path)
+		}
+
+		stored := &http.Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   domain,
+			Path:     path,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+			SameSite: cookie.SameSite,
+		}
+
+		switch {
+		case cookie.MaxAge < 0:
+			j.remove(domain, path, cookie.Name)
+			continue
+		case cookie.MaxAge > 0:
+			stored.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+		case !cookie.Expires.IsZero():
+			if !cookie.Expires.After(now) {
+				j.remove(domain, path, cookie.Name)
+				continue
+			}
+			stored.Expires = cookie.Expires
+		}
+
+		j.store(stored, hostOnly)
+	}
+}
+
+// Cookies returns cookies to send to u
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	host := canonicalHost(u)
+	if host == "" {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+	now := time.Now()
+
+	var matched []*http.Cookie
+	for domain, cookies := range j.entries {
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		for id, cookie := range cookies {
+			if !cookie.Expires.IsZero() && !cookie.Expires.After(now) {
+				delete(cookies, id)
+				delete(j.hostOnly, id)
+				continue
+			}
+			if j.hostOnly[id] && host != domain {
+				continue
+			}
+			if cookie.Secure && !secure {
+				continue
+			}
+			if !pathMatch(path, cookie.Path) {
+				continue
+			}
+			matched = append(matched, cookie)
+		}
+	}
+
+	sort.Slice(matched, func(a, b int) bool {
+		return len(matched[a].Path) > len(matched[b].Path)
+	})
+
+	result := make([]*http.Cookie, len(matched))
+	for i, cookie := range matched {
+		result[i] = &http.Cookie{Name: cookie.Name, Value: cookie.Value}
+	}
+	return result
+}
+
+// All returns copies of all stored cookies with domain, path and expiry
+func (j *Jar) All() []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var all []*http.Cookie
+	for _, cookies := range j.entries {
+		for _, cookie := range cookies {
+			copied := *cookie
+			all = append(all, &copied)
+		}
+	}
+
+	sort.Slice(all, func(a, b int) bool {
+		if all[a].Domain != all[b].Domain {
+			return all[a].Domain < all[b].Domain
+		}
+		if all[a].Path != all[b].Path {
+			return all[a].Path < all[b].Path
+		}
+		return all[a].Name < all[b].Name
+	})
+	return all
+}
+
+// Clear removes all cookies
+func (j *Jar) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = make(map[string]map[string]*http.Cookie)
+	j.hostOnly = make(map[string]bool)
+}
+
+// Export writes cookies in Netscape cookie file format
+func (j *Jar) Export(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# Netscape HTTP Cookie File")
+
+	j.mu.Lock()
+	hostOnly := make(map[string]bool, len(j.hostOnly))
+	for id, v := range j.hostOnly {
+		hostOnly[id] = v
+	}
+	j.mu.Unlock()
+
+	for _, cookie := range j.All() {
+		domain := cookie.Domain
+		subdomains := "FALSE"
+		if !hostOnly[cookieID(cookie.Domain, cookie.Path, cookie.Name)] {
+			domain = "." + domain
+			subdomains = "TRUE"
+		}
+		if cookie.HttpOnly {
+			domain = httpOnlyPrefix + domain
+		}
+
+		var expires int64
+		if !cookie.Expires.IsZero() {
+			expires = cookie.Expires.Unix()
+		}
+
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, subdomains, cookie.Path, strings.ToUpper(strconv.FormatBool(cookie.Secure)),
+			expires, cookie.Name, cookie.Value)
+	}
+
+	return bw.Flush()
+}
+
+// Import reads cookies in Netscape cookie file format
+func (j *Jar) Import(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	now := time.Now()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := strings.HasPrefix(line, httpOnlyPrefix)
+		if httpOnly {
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return fmt.Errorf("invalid cookie line %d", lineNum)
+		}
+
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid expiry on cookie line %d: %w", lineNum, err)
+		}
+
+		cookie := &http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Domain:   strings.ToLower(strings.TrimPrefix(fields[0], ".")),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+		}
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+			if !cookie.Expires.After(now) {
+				continue
+			}
+		}
+
+		j.store(cookie, !strings.EqualFold(fields[1], "TRUE"))
+	}
+
+	return scanner.Err()
+}
+
+// SaveFile exports cookies to file
+func (j *Jar) SaveFile(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return j.Export(file)
+}
+
+// LoadFile imports cookies from file
+func (j *Jar) LoadFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return j.Import(file)
+}
+
+// store saves cookie, mu must be held
+func (j *Jar) store(cookie *http.Cookie, hostOnly bool) {
+	cookies, ok := j.entries[cookie.Domain]
+	if !ok {
+		cookies = make(map[string]*http.Cookie)
+		j.entries[cookie.Domain] = cookies
+	}
+
+	id := cookieID(cookie.Domain, cookie.Path, cookie.Name)
+	cookies[id] = cookie
+	j.hostOnly[id] = hostOnly
+}
+
+// remove deletes cookie, mu must be held
+func (j *Jar) remove(domain, path, name string) {
+	id := cookieID(domain, path, name)
+	if cookies, ok := j.entries[domain]; ok {
+		delete(cookies, id)
+	}
+	delete(j.hostOnly, id)
+}
+
+// cookieID unique key of cookie
+func cookieID(domain, path, name string) string {
+	return domain + ";" + path + ";" + name
+}
+
+// canonicalHost returns lowercase host without port
+func canonicalHost(u *url.URL) string {
+	return strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+}
+
+// cookieDomain validates Domain attribute against host
+func cookieDomain(host, domain string) (string, bool, bool) {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	if domain == "" || domain == host {
+		return host, domain == "", true
+	}
+
+	if net.ParseIP(host) != nil {
+		return "", false, false
+	}
+	if !strings.HasSuffix(host, "."+domain) {
+		return "", false, false
+	}
+	if suffix, _ := publicsuffix.PublicSuffix(domain); suffix == domain {
+		return "", false, false
+	}
+	return domain, false, true
+}
+
+// defaultPath returns cookie path for request path
+func defaultPath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+// pathMatch checks if request path is inside cookie path
+func pathMatch(requestPath, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
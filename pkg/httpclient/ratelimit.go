@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter token bucket shared by every request of the client
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter creates token bucket, rps <= 0 disables limiting
+func newLimiter(rps float64, burst int) *limiter {
+	l := &limiter{}
+	l.set(rps, burst)
+	l.tokens = l.burst
+	return l
+}
+
+// set changes rate and burst, can be called while requests are waiting
+func (l *limiter) set(rps float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+
+	l.rate = rps
+	if burst <= 0 {
+		burst = 1
+	}
+	l.burst = float64(burst)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// limit returns current requests per second
+func (l *limiter) limit() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// wait blocks until token is available or ctx is done
+func (l *limiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.rate <= 0 {
+			l.mu.Unlock()
+			return nil
+		}
+
+		now := time.Now()
+		l.refill(now)
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+
+		delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		// rate may change while sleeping, so check again at least every second
+		if delay > time.Second {
+			delay = time.Second
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// refill adds tokens for elapsed time, mu must be held
+func (l *limiter) refill(now time.Time) {
+	if !l.last.IsZero() && l.rate > 0 {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
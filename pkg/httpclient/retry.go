@@ -0,0 +1,124 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// Retryable error classes for types.RetryPolicy.RetryErrors
+const (
+	ErrorTimeout = "timeout"
+	ErrorReset   = "reset"
+	ErrorRefused = "refused"
+	ErrorEOF     = "eof"
+	ErrorDNS     = "dns"
+)
+
+// defaultRetryErrors used when policy has no error classes
+var defaultRetryErrors = []string{ErrorTimeout, ErrorReset, ErrorRefused, ErrorEOF}
+
+type attemptsKey struct{}
+
+// WithAttemptCounter returns ctx in which Do records number of attempts
+func WithAttemptCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptsKey{}, new(atomic.Int64))
+}
+
+// Attempts returns number of attempts recorded in ctx
+func Attempts(ctx context.Context) int {
+	if counter, ok := ctx.Value(attemptsKey{}).(*atomic.Int64); ok {
+		return int(counter.Load())
+	}
+	return 0
+}
+
+// countAttempt increments attempts counter of ctx if any
+func countAttempt(ctx context.Context) {
+	if counter, ok := ctx.Value(attemptsKey{}).(*atomic.Int64); ok {
+		counter.Add(1)
+	}
+}
+
+// classifyError returns error class or empty string if error is unknown
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return ErrorDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		strings.Contains(err.Error(), "connection reset"):
+		return ErrorReset
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorEOF
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	}
+	return ""
+}
+
+// isIdempotent checks if method can be safely sent twice
+func isIdempotent(method string) bool {
+	switch strings.ToUpper(method) {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// shouldRetry checks if failed attempt can be retried by policy
+func shouldRetry(policy types.RetryPolicy, req *http.Request, resp *http.Response, err error) bool {
+	if policy.IdempotentOnly && !isIdempotent(req.Method) {
+		return false
+	}
+
+	if err != nil {
+		class := classifyError(err)
+		if class == "" {
+			return false
+		}
+
+		classes := policy.RetryErrors
+		if len(classes) == 0 {
+			classes = defaultRetryErrors
+		}
+		for _, c := range classes {
+			if c == class {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, code := range policy.RetryStatus {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// cancelBody cancels attempt context when body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
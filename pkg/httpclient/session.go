@@ -0,0 +1,338 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// maxMarkerBody max body size checked for expired session marker
+const maxMarkerBody = 1 << 20
+
+type sessionKey struct{}
+
+// request marks stored in ctx under sessionKey
+const (
+	markLogin = iota + 1
+	markResent
+)
+
+// session state of login flow
+type session struct {
+	config     types.LoginConfig
+	tokenRegex *regexp.Regexp
+
+	// login serializes logins, generation grows after each successful one
+	login      sync.Mutex
+	mu         sync.RWMutex
+	generation int
+	token      string
+}
+
+// newSession creates session from login cfg
+func newSession(config types.LoginConfig) (*session, error) {
+	s := &session{config: config}
+	if config.TokenRegex != "" {
+		re, err := regexp.Compile(config.TokenRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token regex: %w", err)
+		}
+		s.tokenRegex = re
+	}
+	if s.config.Method == "" {
+		s.config.Method = http.MethodPost
+	}
+	if s.config.TokenHeader == "" {
+		s.config.TokenHeader = "Authorization"
+		if s.config.TokenPrefix == "" {
+			s.config.TokenPrefix = "Bearer "
+		}
+	}
+	return s, nil
+}
+
+// Login runs login step and stores session token and cookies
+func (c *Client) Login(ctx context.Context) error {
+	if c.session == nil {
+		return errors.New("login is not configured")
+	}
+	return c.relogin(ctx, c.session.gen())
+}
+
+// EnsureLogin runs login step unless session already exists
+func (c *Client) EnsureLogin(ctx context.Context) error {
+	if c.session == nil || c.session.gen() > 0 {
+		return nil
+	}
+	return c.Login(ctx)
+}
+
+// relogin logs in unless someone did it after seen generation
+func (c *Client) relogin(ctx context.Context, seen int) error {
+	s := c.session
+
+	s.login.Lock()
+	defer s.login.Unlock()
+
+	if s.gen() != seen {
+		return nil
+	}
+
+	contentType := s.config.ContentType
+	if contentType == "" && s.config.Body != "" {
+		contentType = "application/x-www-form-urlencoded"
+		if strings.HasPrefix(strings.TrimSpace(s.config.Body), "{") {
+			contentType = "application/json"
+		}
+	}
+
+	escape := func(v string) string { return v }
+	switch {
+	case strings.Contains(contentType, "json"):
+		escape = func(v string) string {
+			quoted, _ := json.Marshal(v)
+			return string(quoted[1 : len(quoted)-1])
+		}
+	case strings.Contains(contentType, "x-www-form-urlencoded"):
+		escape = url.QueryEscape
+	}
+
+	var body io.Reader
+	if s.config.Body != "" {
+		bodyReplacer := strings.NewReplacer(
+			"{{username}}", escape(s.config.Username),
+			"{{password}}", escape(s.config.Password),
+		)
+		body = strings.NewReader(bodyReplacer.Replace(s.config.Body))
+	}
+
+	ctx = context.WithValue(ctx, sessionKey{}, markLogin)
+	req, err := http.NewRequestWithContext(ctx, s.config.Method, s.config.URL, body)
+	if err != nil {
+		return fmt.Errorf("failed to create login request: %w", err)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	replacer := strings.NewReplacer("{{username}}", s.config.Username, "{{password}}", s.config.Password)
+	for k, v := range s.config.Headers {
+		req.Header.Set(k, replacer.Replace(v))
+	}
+
+	// cookies already in jar, e.g. static Config.Cookies, do not prove login worked
+	before := c.jar.Cookies(req.URL)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("login request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read login response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("login failed with status %d", resp.StatusCode)
+	}
+
+	token, err := s.extractToken(resp, respBody)
+	if err != nil {
+		return err
+	}
+
+	// login cookies are stored by jar, including ones set on redirects
+	cookies := changedCookies(before, c.jar.Cookies(req.URL))
+	for _, name := range s.config.CookieNames {
+		if !hasCookie(cookies, name) {
+			return fmt.Errorf("login response did not set cookie %q", name)
+		}
+	}
+
+	if token == "" && len(cookies) == 0 {
+		return errors.New("login response set no token or cookies")
+	}
+
+	s.mu.Lock()
+	s.token = token
+	s.generation++
+	s.mu.Unlock()
+
+	return nil
+}
+
+// gen returns login generation
+func (s *session) gen() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.generation
+}
+
+// extractToken gets token from login response
+func (s *session) extractToken(resp *http.Response, body []byte) (string, error) {
+	if s.config.TokenPath != "" {
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return "", fmt.Errorf("login response is not JSON: %w", err)
+		}
+		token, ok := lookupPath(data, s.config.TokenPath)
+		if !ok {
+			return "", fmt.Errorf("token not found at %q", s.config.TokenPath)
+		}
+		return token, nil
+	}
+
+	if s.tokenRegex != nil {
+		match := s.tokenRegex.FindSubmatch(body)
+		if match == nil {
+			return "", errors.New("token regex does not match login response")
+		}
+		if len(match) > 1 {
+			return string(match[1]), nil
+		}
+		return string(match[0]), nil
+	}
+
+	if s.config.TokenResponseHeader != "" {
+		token := resp.Header.Get(s.config.TokenResponseHeader)
+		if token == "" {
+			return "", fmt.Errorf("login response has no %s header", s.config.TokenResponseHeader)
+		}
+		return token, nil
+	}
+
+	return "", nil
+}
+
+// apply attaches session token to request
+func (s *session) apply(req *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.token != "" && req.Header.Get(s.config.TokenHeader) == "" {
+		req.Header.Set(s.config.TokenHeader, s.config.TokenPrefix+s.token)
+	}
+}
+
+// strip removes old session token before request is sent again
+func (s *session) strip(req *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.token != "" {
+		req.Header.Del(s.config.TokenHeader)
+	}
+}
+
+// expired checks if response means session is gone, body is restored after check
+func (s *session) expired(resp *http.Response) bool {
+	if contains(s.config.ExpiredStatus, resp.StatusCode) {
+		return true
+	}
+
+	if s.config.ExpiredRedirect != "" {
+		if strings.Contains(resp.Header.Get("Location"), s.config.ExpiredRedirect) {
+			return true
+		}
+		if resp.Request != nil && resp.Request.URL != nil &&
+			strings.Contains(resp.Request.URL.String(), s.config.ExpiredRedirect) {
+			return true
+		}
+	}
+
+	if s.config.ExpiredMarker != "" {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxMarkerBody))
+		rest := resp.Body
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), rest), rest}
+		if err == nil && bytes.Contains(body, []byte(s.config.ExpiredMarker)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sessionMark returns mark of request ctx, 0 for ordinary requests
+func sessionMark(ctx context.Context) int {
+	mark, _ := ctx.Value(sessionKey{}).(int)
+	return mark
+}
+
+// lookupPath walks dotted path in decoded JSON
+func lookupPath(data interface{}, path string) (string, bool) {
+	for _, key := range strings.Split(path, ".") {
+		switch v := data.(type) {
+		case map[string]interface{}:
+			data = v[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return "", false
+			}
+			data = v[i]
+		default:
+			return "", false
+		}
+	}
+
+	switch v := data.(type) {
+	case string:
+		return v, v != ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// hasCookie checks if cookie with name is present
+func hasCookie(cookies []*http.Cookie, name string) bool {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// changedCookies returns cookies of after that are missing in before or have other value
+func changedCookies(before, after []*http.Cookie) []*http.Cookie {
+	values := make(map[string]string, len(before))
+	for _, cookie := range before {
+		values[cookie.Name] = cookie.Value
+	}
+
+	var changed []*http.Cookie
+	for _, cookie := range after {
+		if value, ok := values[cookie.Name]; !ok || value != cookie.Value {
+			changed = append(changed, cookie)
+		}
+	}
+	return changed
+}
+
+// contains checks if slice has value
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
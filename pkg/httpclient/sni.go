@@ -0,0 +1,88 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// serverNameKey context key of TLS server name override
+type serverNameKey struct{}
+
+// serverNameOverride TLS server name of requests, insecure skips certificate verification
+type serverNameOverride struct {
+	name     string
+	insecure bool
+}
+
+// WithServerName makes requests with ctx send name in TLS SNI instead of URL host, certificate is verified
+// against name unless client skips verification, used with req.Host to reach virtual hosts behind fixed address,
+// ignored behind proxy
+func WithServerName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, serverNameKey{}, serverNameOverride{name: name})
+}
+
+// WithInsecureServerName works like WithServerName but skips certificate verification,
+// for vhost scans where name is a guess the certificate cannot be expected to match
+func WithInsecureServerName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, serverNameKey{}, serverNameOverride{name: name, insecure: true})
+}
+
+// serverName returns SNI override of ctx
+func serverName(ctx context.Context) serverNameOverride {
+	override, _ := ctx.Value(serverNameKey{}).(serverNameOverride)
+	return override
+}
+
+// sniTransport sends requests with SNI override over fresh connections so pooled ones are not mixed up
+type sniTransport struct {
+	base *http.Transport
+	sni  *http.Transport
+}
+
+// newSNITransport wraps base, dialer is used for SNI override connections
+func newSNITransport(base *http.Transport, dialer *net.Dialer) *sniTransport {
+	sni := base.Clone()
+	sni.DisableKeepAlives = true
+	sni.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		override := serverName(ctx)
+		config := base.TLSClientConfig.Clone()
+		if config == nil {
+			config = &tls.Config{}
+		}
+		config.ServerName = override.name
+		if override.insecure {
+			config.InsecureSkipVerify = true
+		}
+
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+
+	return &sniTransport{base: base, sni: sni}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *sniTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme == "https" && serverName(req.Context()).name != "" {
+		return t.sni.RoundTrip(req)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// setProxy sets proxy of both transports
+func (t *sniTransport) setProxy(proxy func(*http.Request) (*url.URL, error)) {
+	t.base.Proxy = proxy
+	t.sni.Proxy = proxy
+}
@@ -3,12 +3,17 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Z-egorov/Go-Brute-Scanner/pkg/bruteforce"
 	"github.com/Z-egorov/Go-Brute-Scanner/pkg/discovery"
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/gitdump"
 	"github.com/Z-egorov/Go-Brute-Scanner/pkg/httpclient"
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/scope"
 	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
 	"github.com/Z-egorov/Go-Brute-Scanner/pkg/wordlists"
 )
@@ -21,7 +26,20 @@ type Scanner interface {
 	Discover(ctx context.Context) ([]types.Endpoint, error)
 	Scan(ctx context.Context, methods []string, delay time.Duration) ([]types.ScanResult, error)
 	ScanWithWordlist(ctx context.Context, wordlist []string, methods []string, concurrency int, delay time.Duration) ([]types.ScanResult, error)
+	StreamWithWordlist(ctx context.Context, wordlist []string, methods []string, concurrency int, delay time.Duration, fn func(types.ScanResult) error) error
+	ResultsWithWordlist(ctx context.Context, wordlist []string, methods []string, concurrency int, delay time.Duration) (<-chan types.ScanResult, <-chan error)
+	ScanTemplate(ctx context.Context, tmpl types.RequestTemplate, wordlist []string, concurrency int, delay time.Duration) ([]types.ScanResult, error)
+	ScanAttack(ctx context.Context, tmpl types.RequestTemplate, payloads map[string][]string, mode bruteforce.AttackMode, concurrency int, delay time.Duration) ([]types.ScanResult, error)
+	ScanVhosts(ctx context.Context, names []string, concurrency int, delay time.Duration) ([]types.ScanResult, error)
+	DumpGit(ctx context.Context, gitURL, outDir string) (*gitdump.Repository, error)
+	ImportSpec(path string) ([]types.Endpoint, error)
+	FindParams(ctx context.Context, endpoints []types.Endpoint, names []string, concurrency int) ([]types.Endpoint, error)
+	ResumeScan(ctx context.Context, checkpointFile string, concurrency int, delay time.Duration) ([]types.ScanResult, error)
+	Pause() error
+	Resume()
 	GetStats() types.Stats
+	CookieJar() *httpclient.Jar
+	SetRateLimit(rps int)
 	Stop() error
 }
 
@@ -40,18 +58,28 @@ type scannerImpl struct {
 // New creates new scanner
 func New(baseURL string, opts ...Option) (Scanner, error) {
 	config := types.Config{
-		BaseURL:      baseURL,
-		Timeout:      10 * time.Second,
-		UserAgent:    "GoBruteScanner/1.0",
-		Workers:      5,
-		MaxRedirects: 3,
-		ScanDepth:    2,
-		UseProxies:   false,
-		ProxyRotate:  false,
-		RateLimit:    10,
-		Headers:      make(map[string]string),
-		Cookies:      make(map[string]string),
-		InsecureSSL:  false,
+		BaseURL:         baseURL,
+		Timeout:         10 * time.Second,
+		UserAgent:       "GoBruteScanner/1.0",
+		Workers:         5,
+		MaxRedirects:    3,
+		ScanDepth:       2,
+		Calibrate:       true,
+		MaxTasks:        bruteforce.DefaultMaxTasks,
+		UseProxies:      false,
+		ProxyRotate:     false,
+		RateLimit:       10,
+		RateBurst:       1,
+		ThrottleRetries: 3,
+		BackoffBase:     500 * time.Millisecond,
+		BackoffMax:      30 * time.Second,
+		Retry: types.RetryPolicy{
+			MaxAttempts: 3,
+			RetryStatus: []int{502, 504},
+		},
+		Headers:     make(map[string]string),
+		Cookies:     make(map[string]string),
+		InsecureSSL: false,
 	}
 
 	for _, opt := range opts {
@@ -63,22 +91,68 @@ func New(baseURL string, opts ...Option) (Scanner, error) {
 		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
 	}
 
-	crawler := discovery.NewCrawler(client, config.ScanDepth)
+	if config.CookieFile != "" {
+		if err := client.Jar().LoadFile(config.CookieFile); err != nil {
+			return nil, fmt.Errorf("failed to load cookies: %w", err)
+		}
+	}
 
-	bfScanner := bruteforce.NewScanner(client)
+	sc, err := scope.New(config.BaseURL, config.Scope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scope: %w", err)
+	}
 
-	wl := wordlists.New()
+	crawler := discovery.NewCrawler(client, config.ScanDepth,
+		discovery.WithWorkers(config.Workers),
+		discovery.WithMaxPages(config.MaxPages),
+		discovery.WithScope(sc),
+		discovery.WithSeeds(config.Seeds),
+		discovery.WithHonorRobots(config.HonorRobots),
+		discovery.WithOpenAPI(config.OpenAPI),
+	)
 
-	return &scannerImpl{
+	matcher, filter, err := bruteforce.NewMatchers(config.Match)
+	if err != nil {
+		return nil, err
+	}
+
+	bfOpts := []bruteforce.Option{
+		bruteforce.WithCalibration(config.Calibrate),
+		bruteforce.WithRecursion(config.RecursionDepth, config.RecursionStatus),
+		bruteforce.WithMaxTasks(config.MaxTasks),
+		bruteforce.WithExtensions(config.Extensions...),
+		bruteforce.WithSuffixes(config.Suffixes...),
+		bruteforce.WithMethodDiscovery(config.MethodDiscovery, config.ExtraMethods...),
+		bruteforce.WithMethodOverride(config.MethodOverride, config.OverrideMethods...),
+		bruteforce.WithParamBatch(config.ParamBatch),
+		bruteforce.WithBypass(config.Bypass),
+		bruteforce.WithArtifacts(config.Artifacts),
+		bruteforce.WithGitDump(config.GitDump, config.GitDumpDir),
+		bruteforce.WithScope(sc),
+	}
+	if matcher != nil {
+		bfOpts = append(bfOpts, bruteforce.WithMatcher(matcher))
+	}
+	if filter != nil {
+		bfOpts = append(bfOpts, bruteforce.WithFilter(filter))
+	}
+
+	s := &scannerImpl{
 		config:     config,
 		client:     client,
 		discoverer: crawler,
-		bf:         bfScanner,
-		wordlists:  wl,
+		wordlists:  wordlists.New(),
 		stats: types.Stats{
 			StartTime: time.Now(),
 		},
-	}, nil
+	}
+
+	if config.CheckpointFile != "" {
+		bfOpts = append(bfOpts, bruteforce.WithCheckpoint(config.CheckpointInterval, s.saveCheckpoint))
+	}
+	s.bf = bruteforce.NewScanner(client, bfOpts...)
+
+	return s, nil
 }
 
 // WithTimeout sets timeout
@@ -109,6 +183,41 @@ func WithScanDepth(depth int) Option {
 	}
 }
 
+// WithMaxPages caps pages fetched by crawler, n <= 0 means unlimited
+func WithMaxPages(n int) Option {
+	return func(c *types.Config) {
+		c.MaxPages = n
+	}
+}
+
+// WithSeeds seeds crawl with robots.txt paths and sitemap URLs, off by default
+func WithSeeds(enabled bool) Option {
+	return func(c *types.Config) {
+		c.Seeds = enabled
+	}
+}
+
+// WithHonorRobots skips pages robots.txt disallows while crawling
+func WithHonorRobots(enabled bool) Option {
+	return func(c *types.Config) {
+		c.HonorRobots = enabled
+	}
+}
+
+// WithOpenAPI probes well-known OpenAPI/Swagger locations while crawling and imports their operations, off by default
+func WithOpenAPI(enabled bool) Option {
+	return func(c *types.Config) {
+		c.OpenAPI = enabled
+	}
+}
+
+// WithScope limits URLs crawler and bruteforcer request, out-of-scope endpoints are still reported
+func WithScope(scope types.ScopeConfig) Option {
+	return func(c *types.Config) {
+		c.Scope = scope
+	}
+}
+
 // WithProxies enables all proxies
 func WithProxies(rotate bool) Option {
 	return func(c *types.Config) {
@@ -124,22 +233,175 @@ func WithProxyURLs(urls ...string) Option {
 	}
 }
 
+// WithRateLimit sets global requests per second and burst, rps <= 0 disables limiting
+func WithRateLimit(rps, burst int) Option {
+	return func(c *types.Config) {
+		c.RateLimit = rps
+		c.RateBurst = burst
+	}
+}
+
+// WithBackoff sets retries of 429/503 responses and bounds of backoff delay
+func WithBackoff(retries int, base, max time.Duration) Option {
+	return func(c *types.Config) {
+		c.ThrottleRetries = retries
+		c.BackoffBase = base
+		c.BackoffMax = max
+	}
+}
+
+// WithRetryPolicy sets retry policy for transport errors
+func WithRetryPolicy(policy types.RetryPolicy) Option {
+	return func(c *types.Config) {
+		c.Retry = policy
+	}
+}
+
+// WithAuth sets credentials for every crawler and bruteforce request
+func WithAuth(auth types.AuthConfig) Option {
+	return func(c *types.Config) {
+		c.Auth = &auth
+	}
+}
+
+// WithLogin sets login step executed before scan and on session expiry
+func WithLogin(login types.LoginConfig) Option {
+	return func(c *types.Config) {
+		c.Login = &login
+	}
+}
+
+// WithCookieFile imports cookies from Netscape cookie file
+func WithCookieFile(filename string) Option {
+	return func(c *types.Config) {
+		c.CookieFile = filename
+	}
+}
+
+// WithCalibration enables wildcard and soft-404 calibration, on by default like bruteforce.NewScanner
+func WithCalibration(enabled bool) Option {
+	return func(c *types.Config) {
+		c.Calibrate = enabled
+	}
+}
+
+// WithMatchConfig sets matchers and filters of bruteforce results
+func WithMatchConfig(match types.MatchConfig) Option {
+	return func(c *types.Config) {
+		c.Match = match
+	}
+}
+
+// WithRecursion scans wordlist again under found directories up to depth,
+// statusCodes trigger recursion, none keeps default set
+func WithRecursion(depth int, statusCodes ...int) Option {
+	return func(c *types.Config) {
+		c.RecursionDepth = depth
+		c.RecursionStatus = statusCodes
+	}
+}
+
+// WithMaxTasks caps bruteforce tasks added by recursion and git tracked paths, dropped ones are counted in
+// Stats.Truncated, 0 means unlimited
+func WithMaxTasks(n int) Option {
+	return func(c *types.Config) {
+		c.MaxTasks = n
+	}
+}
+
+// WithExtensions expands every word with extensions like "php" or ".json"
+func WithExtensions(extensions ...string) Option {
+	return func(c *types.Config) {
+		c.Extensions = extensions
+	}
+}
+
+// WithSuffixes expands every word and extension variant with suffixes like "~" or ".old"
+func WithSuffixes(suffixes ...string) Option {
+	return func(c *types.Config) {
+		c.Suffixes = suffixes
+	}
+}
+
+// WithMethodDiscovery sends OPTIONS and other methods only to existing paths,
+// extra methods like PATCH or custom verbs are tried where path allows them
+func WithMethodDiscovery(extra ...string) Option {
+	return func(c *types.Config) {
+		c.MethodDiscovery = true
+		c.ExtraMethods = extra
+	}
+}
+
+// WithMethodOverride probes confirmed paths with method override headers and _method parameters,
+// empty methods tries DELETE, PUT and PATCH
+func WithMethodOverride(methods ...string) Option {
+	return func(c *types.Config) {
+		c.MethodOverride = true
+		c.OverrideMethods = methods
+	}
+}
+
+// WithParamBatch sets how many parameter names FindParams sends in one request, n <= 0 keeps default
+func WithParamBatch(n int) Option {
+	return func(c *types.Config) {
+		c.ParamBatch = n
+	}
+}
+
+// WithBypass re-tests 401 and 403 hits with path normalization and header tricks
+func WithBypass(enabled bool) Option {
+	return func(c *types.Config) {
+		c.Bypass = enabled
+	}
+}
+
+// WithArtifacts probes backup copies, swap files, archives, VCS metadata and source maps of found paths
+func WithArtifacts(enabled bool) Option {
+	return func(c *types.Config) {
+		c.Artifacts = enabled
+	}
+}
+
+// WithGitDump reconstructs exposed .git directories found by artifact probes and scans tracked paths first,
+// non-empty outDir gets blobs written to it, artifact probes are enabled too
+func WithGitDump(outDir string) Option {
+	return func(c *types.Config) {
+		c.Artifacts = true
+		c.GitDump = true
+		c.GitDumpDir = outDir
+	}
+}
+
+// WithCheckpoint saves wordlist scan state to path every interval and when scan ends or is paused,
+// interval <= 0 keeps bruteforce.DefaultCheckpointInterval
+func WithCheckpoint(path string, interval time.Duration) Option {
+	return func(c *types.Config) {
+		c.CheckpointFile = path
+		c.CheckpointInterval = interval
+	}
+}
+
 // Discover endpoints auto-detect
 func (s *scannerImpl) Discover(ctx context.Context) ([]types.Endpoint, error) {
 	s.mu.Lock()
 	s.stats.DiscoveryStartTime = time.Now()
 	s.mu.Unlock()
 
-	endpoints, err := s.discoverer.Crawl(ctx, s.config.BaseURL)
-	if err != nil {
-		return nil, fmt.Errorf("crawling failed: %w", err)
+	if err := s.client.EnsureLogin(ctx); err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
 	}
 
+	// endpoints found before crawl was interrupted come back with error
+	endpoints, err := s.discoverer.Crawl(ctx, s.config.BaseURL)
+
 	s.mu.Lock()
 	s.stats.TotalDiscovered = len(endpoints)
 	s.stats.DiscoveryDuration = time.Since(s.stats.DiscoveryStartTime)
 	s.mu.Unlock()
 
+	if err != nil {
+		return endpoints, fmt.Errorf("crawling failed: %w", err)
+	}
 	return endpoints, nil
 }
 
@@ -150,62 +412,376 @@ func (s *scannerImpl) Scan(ctx context.Context, methods []string, delay time.Dur
 
 // ScanWithWordlist scan with wordlist
 func (s *scannerImpl) ScanWithWordlist(ctx context.Context, wordlist []string, methods []string, concurrency int, delay time.Duration) ([]types.ScanResult, error) {
+	ctx, cancel, err := s.startScan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	if len(methods) == 0 {
+		methods = []string{"GET", "POST", "PUT", "DELETE"}
+	}
+
+	// results come back along with checkpoint errors
+	bruteResults, err := s.bf.ScanWordlist(ctx, s.config.BaseURL, wordlist, methods, concurrency, delay)
+	results := s.finishScan(bruteResults, "bruteforce")
+	if err != nil {
+		return results, fmt.Errorf("brute force scan failed: %w", err)
+	}
+
+	return results, nil
+}
+
+// StreamWithWordlist scans with wordlist and hands every result with Body to fn as it completes,
+// fn blocks scan workers until it returns, non-nil error stops scan and is returned
+func (s *scannerImpl) StreamWithWordlist(ctx context.Context, wordlist []string, methods []string, concurrency int, delay time.Duration, fn func(types.ScanResult) error) error {
+	ctx, cancel, err := s.startScan(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if len(methods) == 0 {
+		methods = []string{"GET", "POST", "PUT", "DELETE"}
+	}
+
+	err = s.bf.StreamWordlist(ctx, s.config.BaseURL, wordlist, methods, concurrency, delay, func(r types.BruteResult) error {
+		s.countResult(r)
+		result := toScanResult(r, "bruteforce")
+		result.Body = r.Body
+		return fn(result)
+	})
+
 	s.mu.Lock()
-	s.stats.ScanStartTime = time.Now()
+	s.stats.ScanDuration = time.Since(s.stats.ScanStartTime)
+	s.stats.Duration = time.Since(s.stats.StartTime)
 	s.mu.Unlock()
 
-	ctx, cancel := context.WithCancel(ctx)
+	return err
+}
+
+// ResultsWithWordlist runs StreamWithWordlist in background, results channel is unbuffered so slow reader slows scan,
+// both channels are closed when scan ends, error channel gets scan error if any, cancel ctx to stop reading early
+func (s *scannerImpl) ResultsWithWordlist(ctx context.Context, wordlist []string, methods []string, concurrency int, delay time.Duration) (<-chan types.ScanResult, <-chan error) {
+	results := make(chan types.ScanResult)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(results)
+
+		err := s.StreamWithWordlist(ctx, wordlist, methods, concurrency, delay, func(r types.ScanResult) error {
+			select {
+			case results <- r:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return results, errs
+}
+
+// ResumeScan continues wordlist scan saved in checkpointFile, scanner should be created with options of
+// interrupted scan, results include ones found before interruption
+func (s *scannerImpl) ResumeScan(ctx context.Context, checkpointFile string, concurrency int, delay time.Duration) ([]types.ScanResult, error) {
+	cp, err := bruteforce.LoadCheckpoint(checkpointFile)
+	if err != nil {
+		return nil, err
+	}
+	if cp.BaseURL != s.config.BaseURL {
+		return nil, fmt.Errorf("checkpoint is for %s, not %s", cp.BaseURL, s.config.BaseURL)
+	}
+
+	ctx, cancel, err := s.startScan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	// GetStats adds client counters, ones of interrupted scan run by this scanner are already in checkpoint
+	client := s.client.GetStats()
 	s.mu.Lock()
-	s.cancelFunc = cancel
+	s.stats.TotalRequests = cp.Stats.TotalRequests - client.Requests
+	s.stats.Retries = cp.Stats.Retries - client.Retries
+	s.stats.Throttled = cp.Stats.Throttled - client.Throttled
+	s.stats.Slowdowns = cp.Stats.Slowdowns - client.Slowdowns
+	s.stats.TotalDiscovered = cp.Stats.TotalDiscovered
+	// resumed results include checkpointed ones and are counted again
+	s.stats.Successful = 0
+	s.stats.Failed = 0
+	s.stats.Wildcards = 0
 	s.mu.Unlock()
 
+	bruteResults, err := s.bf.ResumeWordlist(ctx, cp, concurrency, delay)
+	results := s.finishScan(bruteResults, "bruteforce")
+	if err != nil {
+		return results, fmt.Errorf("brute force scan failed: %w", err)
+	}
+
+	return results, nil
+}
+
+// Pause stops scan workers after their current request and saves checkpoint if enabled
+func (s *scannerImpl) Pause() error {
+	return s.bf.Pause()
+}
+
+// Resume continues paused scan
+func (s *scannerImpl) Resume() {
+	s.bf.Resume()
+}
+
+// saveCheckpoint writes bruteforce checkpoint with current statistics
+func (s *scannerImpl) saveCheckpoint(cp *bruteforce.Checkpoint) error {
+	cp.Stats = s.GetStats()
+	return cp.Save(s.config.CheckpointFile)
+}
+
+// ScanTemplate fuzzes request template with wordlist, relative template URL is resolved against base URL
+func (s *scannerImpl) ScanTemplate(ctx context.Context, tmpl types.RequestTemplate, wordlist []string, concurrency int, delay time.Duration) ([]types.ScanResult, error) {
+	ctx, cancel, err := s.startScan(ctx)
+	if err != nil {
+		return nil, err
+	}
 	defer cancel()
 
-	if len(methods) == 0 {
-		methods = []string{"GET", "POST", "PUT", "DELETE"}
+	tmpl.URL = s.resolveURL(tmpl.URL)
+
+	bruteResults, err := s.bf.ScanTemplate(ctx, tmpl, wordlist, concurrency, delay)
+	if err != nil {
+		return nil, fmt.Errorf("fuzzing failed: %w", err)
 	}
 
-	bruteResults, err := s.bf.ScanWordlist(ctx, s.config.BaseURL, wordlist, methods, concurrency, delay)
+	return s.finishScan(bruteResults, "fuzz"), nil
+}
+
+// ScanAttack fuzzes template with wordlist per keyword combined by clusterbomb, pitchfork or sniper mode
+func (s *scannerImpl) ScanAttack(ctx context.Context, tmpl types.RequestTemplate, payloads map[string][]string, mode bruteforce.AttackMode, concurrency int, delay time.Duration) ([]types.ScanResult, error) {
+	ctx, cancel, err := s.startScan(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("brute force scan failed: %w", err)
+		return nil, err
 	}
+	defer cancel()
 
-	scanResults := make([]types.ScanResult, len(bruteResults))
-	for i, r := range bruteResults {
-		scanResults[i] = types.ScanResult{
-			URL:        r.URL,
-			Method:     r.Method,
-			StatusCode: r.StatusCode,
-			Size:       r.Size,
-			Headers:    r.Headers,
-			Title:      r.Title,
-			FoundVia:   "bruteforce",
-			Timestamp:  r.Timestamp,
-			Error:      r.Error,
+	tmpl.URL = s.resolveURL(tmpl.URL)
+
+	bruteResults, err := s.bf.ScanAttack(ctx, tmpl, payloads, mode, concurrency, delay)
+	if err != nil {
+		return nil, fmt.Errorf("fuzzing failed: %w", err)
+	}
+
+	return s.finishScan(bruteResults, "fuzz"), nil
+}
+
+// ScanVhosts keeps connecting to base URL while Host header and TLS SNI take names as subdomains of base host,
+// base URL with IP address takes names as full hostnames
+func (s *scannerImpl) ScanVhosts(ctx context.Context, names []string, concurrency int, delay time.Duration) ([]types.ScanResult, error) {
+	ctx, cancel, err := s.startScan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	domain := ""
+	if base, err := url.Parse(s.config.BaseURL); err == nil && net.ParseIP(base.Hostname()) == nil {
+		domain = base.Hostname()
+	}
+
+	bruteResults, err := s.bf.ScanVhosts(ctx, s.config.BaseURL, domain, names, concurrency, delay)
+	if err != nil {
+		return nil, fmt.Errorf("vhost scan failed: %w", err)
+	}
+
+	return s.finishScan(bruteResults, "vhost"), nil
+}
+
+// DumpGit lists tracked files of exposed .git directory, relative gitURL is resolved against base URL,
+// non-empty outDir gets blobs written to it
+func (s *scannerImpl) DumpGit(ctx context.Context, gitURL, outDir string) (*gitdump.Repository, error) {
+	if err := s.client.EnsureLogin(ctx); err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+
+	repo, err := gitdump.New(s.client, s.config.Workers).Dump(ctx, s.resolveURL(gitURL), outDir)
+	if err != nil {
+		return repo, fmt.Errorf("git dump failed: %w", err)
+	}
+	return repo, nil
+}
+
+// ImportSpec converts operations of local OpenAPI/Swagger file to endpoints, specs without servers
+// are resolved against base URL
+func (s *scannerImpl) ImportSpec(path string) ([]types.Endpoint, error) {
+	endpoints, err := s.discoverer.ImportSpec(path, s.config.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("spec import failed: %w", err)
+	}
+	return endpoints, nil
+}
+
+// FindParams discovers hidden query and body parameters of endpoints, nil names uses built-in list
+func (s *scannerImpl) FindParams(ctx context.Context, endpoints []types.Endpoint, names []string, concurrency int) ([]types.Endpoint, error) {
+	ctx, cancel, err := s.startScan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	if names == nil {
+		names = s.wordlists.GetParams()
+	}
+
+	result := make([]types.Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		found, err := s.bf.ScanParams(ctx, endpoint, names, concurrency)
+		if err != nil {
+			if ctx.Err() != nil {
+				return result, fmt.Errorf("parameter discovery failed: %w", err)
+			}
+			found = endpoint
 		}
+		result = append(result, found)
 	}
 
 	s.mu.Lock()
-	s.stats.TotalRequests += len(bruteResults)
+	s.stats.ScanDuration = time.Since(s.stats.ScanStartTime)
+	s.stats.Duration = time.Since(s.stats.StartTime)
+	s.mu.Unlock()
+
+	return result, nil
+}
+
+// startScan marks scan start, makes it cancelable by Stop and logs in
+func (s *scannerImpl) startScan(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	s.mu.Lock()
+	s.stats.ScanStartTime = time.Now()
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancelFunc = cancel
+	s.mu.Unlock()
+
+	if err := s.client.EnsureLogin(ctx); err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("login failed: %w", err)
+	}
+
+	return ctx, cancel, nil
+}
+
+// finishScan converts results and updates statistics
+func (s *scannerImpl) finishScan(bruteResults []types.BruteResult, foundVia string) []types.ScanResult {
+	scanResults := make([]types.ScanResult, len(bruteResults))
+	for i, r := range bruteResults {
+		scanResults[i] = toScanResult(r, foundVia)
+	}
+
 	for _, r := range bruteResults {
-		if r.StatusCode >= 200 && r.StatusCode < 300 {
-			s.stats.Successful++
-		} else if r.StatusCode >= 400 {
-			s.stats.Failed++
-		}
+		s.countResult(r)
 	}
+
+	s.mu.Lock()
 	s.stats.ScanDuration = time.Since(s.stats.ScanStartTime)
 	s.stats.Duration = time.Since(s.stats.StartTime)
 	s.mu.Unlock()
 
-	return scanResults, nil
+	return scanResults
+}
+
+// countResult updates statistics with result
+func (s *scannerImpl) countResult(r types.BruteResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Wildcard {
+		s.stats.Wildcards++
+	} else if r.StatusCode >= 200 && r.StatusCode < 300 {
+		s.stats.Successful++
+	} else if r.StatusCode >= 400 {
+		s.stats.Failed++
+	}
+}
+
+// toScanResult converts bruteforce result
+func toScanResult(r types.BruteResult, foundVia string) types.ScanResult {
+	return types.ScanResult{
+		URL:        r.URL,
+		Method:     r.Method,
+		StatusCode: r.StatusCode,
+		Size:       r.Size,
+		Headers:    r.Headers,
+		Title:      r.Title,
+		FoundVia:   foundVia,
+		Timestamp:  r.Timestamp,
+		Error:      r.Error,
+		Attempts:   r.Attempts,
+		Wildcard:   r.Wildcard,
+		Depth:      r.Depth,
+		Word:       r.Word,
+		Variant:    r.Variant,
+		Payload:    r.Payload,
+		Methods:    r.Methods,
+		Words:      r.Words,
+		Lines:      r.Lines,
+		Duration:   r.Duration,
+
+		Finding:         r.Finding,
+		EffectiveMethod: r.EffectiveMethod,
+		Override:        r.Override,
+		Bypass:          r.Bypass,
+		Parent:          r.Parent,
+		Request:         r.Request,
+		Host:            r.Host,
+		Artifact:        r.Artifact,
+		Files:           r.Files,
+	}
+}
+
+// resolveURL resolves relative ref against base URL, keywords may be anywhere so URL is not parsed
+func (s *scannerImpl) resolveURL(ref string) string {
+	if strings.Contains(ref, "://") {
+		return ref
+	}
+	return strings.TrimRight(s.config.BaseURL, "/") + "/" + strings.TrimPrefix(ref, "/")
 }
 
 // GetStats returns statistics
 func (s *scannerImpl) GetStats() types.Stats {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.stats
+	stats := s.stats
+	s.mu.RUnlock()
+
+	// counters of resumed scan start from checkpoint
+	clientStats := s.client.GetStats()
+	stats.TotalRequests += clientStats.Requests
+	stats.Retries += clientStats.Retries
+	stats.Throttled += clientStats.Throttled
+	stats.Slowdowns += clientStats.Slowdowns
+	stats.Truncated = s.bf.Truncated()
+	stats.OutOfScope = s.bf.OutOfScope()
+
+	return stats
+}
+
+// CookieJar returns cookie jar shared by crawler and bruteforcer
+func (s *scannerImpl) CookieJar() *httpclient.Jar {
+	return s.client.Jar()
+}
+
+// SetRateLimit changes rate limit of running scan
+func (s *scannerImpl) SetRateLimit(rps int) {
+	s.mu.Lock()
+	s.config.RateLimit = rps
+	burst := s.config.RateBurst
+	s.mu.Unlock()
+
+	s.client.SetRateLimit(rps, burst)
 }
 
 // Stop stops scan
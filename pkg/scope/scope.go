@@ -0,0 +1,224 @@
+package scope
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Z-egorov/Go-Brute-Scanner/pkg/types"
+)
+
+// DefaultNever URLs that end session, never requested unless ScopeConfig.Never is set,
+// bruteforce words skipped by it are counted in Stats.OutOfScope
+var DefaultNever = []string{`(?i)/(log|sign)[-_]?(out|off)([/?.#_-]|$)`}
+
+// Reasons URL is out of scope
+const (
+	ReasonInvalid     = "invalid"
+	ReasonScheme      = "scheme"
+	ReasonHost        = "host"
+	ReasonPort        = "port"
+	ReasonPath        = "path"
+	ReasonNotIncluded = "not-included"
+	ReasonExcluded    = "excluded"
+	ReasonNever       = "never"
+)
+
+// Scope decides which URLs may be requested
+type Scope struct {
+	host     string
+	hosts    []string
+	schemes  map[string]bool
+	ports    map[string]bool
+	portList bool
+	prefixes []string
+	include  []*regexp.Regexp
+	exclude  []*regexp.Regexp
+	never    []*regexp.Regexp
+}
+
+// New creates scope around base URL
+func New(baseURL string, config types.ScopeConfig) (*Scope, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	s := &Scope{
+		host:     strings.ToLower(base.Hostname()),
+		schemes:  make(map[string]bool),
+		ports:    make(map[string]bool),
+		prefixes: config.PathPrefixes,
+	}
+
+	for _, host := range config.Hosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			s.hosts = append(s.hosts, host)
+		}
+	}
+
+	schemes := config.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	for _, scheme := range schemes {
+		s.schemes[strings.ToLower(scheme)] = true
+	}
+
+	if len(config.Ports) > 0 {
+		s.portList = true
+		for _, port := range config.Ports {
+			s.ports[strconv.Itoa(port)] = true
+		}
+	} else {
+		basePort := port(base)
+		s.ports[basePort] = true
+		if basePort == defaultPort(base.Scheme) {
+			s.ports["80"] = true
+			s.ports["443"] = true
+		}
+	}
+
+	never := config.Never
+	if never == nil {
+		never = DefaultNever
+	}
+	for _, list := range []struct {
+		patterns []string
+		dst      *[]*regexp.Regexp
+		name     string
+	}{
+		{config.Include, &s.include, "include"},
+		{config.Exclude, &s.exclude, "exclude"},
+		{never, &s.never, "never"},
+	} {
+		for _, pattern := range list.patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s pattern %q: %w", list.name, pattern, err)
+			}
+			*list.dst = append(*list.dst, re)
+		}
+	}
+
+	return s, nil
+}
+
+// Allows checks if URL may be requested, nil scope allows everything
+func (s *Scope) Allows(rawURL string) bool {
+	ok, _ := s.Check(rawURL)
+	return ok
+}
+
+// Check tells if URL may be requested and reason if not
+func (s *Scope) Check(rawURL string) (bool, string) {
+	if s == nil {
+		return true, ""
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, ReasonInvalid
+	}
+
+	switch {
+	case !s.schemes[strings.ToLower(u.Scheme)]:
+		return false, ReasonScheme
+	case !s.allowsHost(strings.ToLower(u.Hostname())):
+		return false, ReasonHost
+	case !s.allowsPort(u):
+		return false, ReasonPort
+	case !s.allowsPath(u.EscapedPath()):
+		return false, ReasonPath
+	case len(s.include) > 0 && !matchAny(s.include, rawURL):
+		return false, ReasonNotIncluded
+	case matchAny(s.exclude, rawURL):
+		return false, ReasonExcluded
+	case matchAny(s.never, rawURL):
+		return false, ReasonNever
+	}
+	return true, ""
+}
+
+// Tag marks endpoint out of scope with reason in metadata
+func Tag(endpoint *types.Endpoint, reason string) {
+	if endpoint.Metadata == nil {
+		endpoint.Metadata = make(map[string]interface{})
+	}
+	endpoint.Metadata["scope"] = types.OutOfScope
+	endpoint.Metadata["scope_reason"] = reason
+}
+
+// allowsHost checks base host, listed hosts and subdomain wildcards
+func (s *Scope) allowsHost(host string) bool {
+	if host == s.host {
+		return true
+	}
+	for _, allowed := range s.hosts {
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsPort checks listed ports, without list other hosts may also use default port of scheme
+func (s *Scope) allowsPort(u *url.URL) bool {
+	p := port(u)
+	if s.ports[p] {
+		return true
+	}
+	return !s.portList && strings.ToLower(u.Hostname()) != s.host && p == defaultPort(u.Scheme)
+}
+
+// allowsPath checks path prefixes on segment boundary, /api allows /api/v1 but not /apiary
+func (s *Scope) allowsPath(path string) bool {
+	if len(s.prefixes) == 0 {
+		return true
+	}
+	if path == "" {
+		path = "/"
+	}
+	for _, prefix := range s.prefixes {
+		prefix = "/" + strings.Trim(prefix, "/")
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// port returns explicit port or default port of scheme
+func port(u *url.URL) string {
+	if p := u.Port(); p != "" {
+		return p
+	}
+	return defaultPort(u.Scheme)
+}
+
+// defaultPort returns default port of http and https
+func defaultPort(scheme string) string {
+	switch strings.ToLower(scheme) {
+	case "https":
+		return "443"
+	case "http":
+		return "80"
+	}
+	return ""
+}
+
+// matchAny checks if any pattern matches s
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
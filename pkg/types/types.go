@@ -1,6 +1,8 @@
 package types
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 )
@@ -34,6 +36,31 @@ type ScanResult struct {
 	FoundVia   string            `json:"found_via"`
 	Timestamp  time.Time         `json:"timestamp"`
 	Error      string            `json:"error,omitempty"`
+	Attempts   int               `json:"attempts,omitempty"`
+	Wildcard   bool              `json:"wildcard,omitempty"`
+	Depth      int               `json:"depth,omitempty"`
+	Word       string            `json:"word,omitempty"`
+	Variant    string            `json:"variant,omitempty"`
+	Payload    map[string]string `json:"payload,omitempty"`
+	Methods    []string          `json:"methods,omitempty"`
+	Words      int               `json:"words,omitempty"`
+	Lines      int               `json:"lines,omitempty"`
+	Duration   time.Duration     `json:"duration,omitempty"`
+
+	// Finding type of non-path result, method override tells EffectiveMethod tunneled through wire Method by Override
+	Finding         string `json:"finding,omitempty"`
+	EffectiveMethod string `json:"effective_method,omitempty"`
+	Override        string `json:"override,omitempty"`
+	// Bypass names variant of blocked Parent that worked, Request is its raw request
+	Bypass  string `json:"bypass,omitempty"`
+	Parent  string `json:"parent,omitempty"`
+	Request string `json:"request,omitempty"`
+	// Host virtual host name the request was sent to
+	Host string `json:"host,omitempty"`
+	// Artifact kind of sensitive artifact derived from Parent
+	Artifact string `json:"artifact,omitempty"`
+	// Files paths tracked by exposed repository
+	Files []string `json:"files,omitempty"`
 }
 
 // Stats scan statistics
@@ -48,6 +75,14 @@ type Stats struct {
 	Duration           time.Duration `json:"duration"`
 	DiscoveryDuration  time.Duration `json:"discovery_duration,omitempty"`
 	ScanDuration       time.Duration `json:"scan_duration,omitempty"`
+	Retries            int           `json:"retries"`
+	Throttled          int           `json:"throttled"`
+	Slowdowns          int           `json:"slowdowns"`
+	Wildcards          int           `json:"wildcards"`
+	// Truncated tasks dropped over MaxTasks
+	Truncated int `json:"truncated,omitempty"`
+	// OutOfScope bruteforce words and endpoints skipped by scope, e.g. logout paths of scope.DefaultNever
+	OutOfScope int `json:"out_of_scope,omitempty"`
 }
 
 // Config scan cfg
@@ -61,13 +96,112 @@ type Config struct {
 	UseProxies   bool              `json:"use_proxies"`
 	ProxyRotate  bool              `json:"proxy_rotate"`
 	RateLimit    int               `json:"rate_limit"`
+	RateBurst    int               `json:"rate_burst"`
 	Headers      map[string]string `json:"headers"`
 	Cookies      map[string]string `json:"cookies"`
+	CookieFile   string            `json:"cookie_file,omitempty"`
+	Calibrate    bool              `json:"calibrate"`
 	InsecureSSL  bool              `json:"insecure_ssl"`
 	ProxyURLs    []string          `json:"proxy_urls"`
+
+	// backoff on 429/503 responses
+	ThrottleRetries int           `json:"throttle_retries"`
+	BackoffBase     time.Duration `json:"backoff_base"`
+	BackoffMax      time.Duration `json:"backoff_max"`
+
+	// recursive bruteforce into found directories
+	RecursionDepth  int   `json:"recursion_depth"`
+	RecursionStatus []int `json:"recursion_status,omitempty"`
+	MaxTasks        int   `json:"max_tasks"`
+
+	// word variants, extensions like "php" and suffixes like "~" or ".old"
+	Extensions []string `json:"extensions,omitempty"`
+	Suffixes   []string `json:"suffixes,omitempty"`
+
+	// method discovery via OPTIONS, Allow and 405 instead of every method on every path
+	MethodDiscovery bool     `json:"method_discovery"`
+	ExtraMethods    []string `json:"extra_methods,omitempty"`
+
+	// method override probes on confirmed paths, POST with X-HTTP-Method-Override or _method
+	MethodOverride  bool     `json:"method_override"`
+	OverrideMethods []string `json:"override_methods,omitempty"`
+
+	// parameter names sent per request by parameter discovery, 0 keeps default
+	ParamBatch int `json:"param_batch,omitempty"`
+
+	// re-test 401/403 hits with path and header variations
+	Bypass bool `json:"bypass"`
+	// probe backups, swap files, archives, VCS metadata and source maps of found paths
+	Artifacts bool `json:"artifacts"`
+	// reconstruct exposed .git, blobs are written to GitDumpDir if set
+	GitDump    bool   `json:"git_dump"`
+	GitDumpDir string `json:"git_dump_dir,omitempty"`
+
+	// crawler limits, seeds from robots.txt and sitemaps, HonorRobots skips disallowed pages
+	MaxPages    int  `json:"max_pages,omitempty"`
+	Seeds       bool `json:"seeds"`
+	HonorRobots bool `json:"honor_robots"`
+	// probe well-known OpenAPI/Swagger spec locations and import their operations
+	OpenAPI bool `json:"openapi"`
+
+	// URLs crawler and bruteforcer may request
+	Scope ScopeConfig `json:"scope"`
+
+	// periodic scan state file to resume interrupted wordlist scans from
+	CheckpointFile     string        `json:"checkpoint_file,omitempty"`
+	CheckpointInterval time.Duration `json:"checkpoint_interval,omitempty"`
+
+	Retry RetryPolicy  `json:"retry"`
+	Auth  *AuthConfig  `json:"auth,omitempty"`
+	Login *LoginConfig `json:"login,omitempty"`
+	Match MatchConfig  `json:"match"`
 }
 
+// MatchConfig ffuf-style matchers and filters of bruteforce results
+// numeric specs are lists like "200,301-399,>1000", time is in milliseconds
+// result is kept if any (or all with Mode "and") matcher matches and no filter matches
+type MatchConfig struct {
+	MatchStatus string `json:"match_status,omitempty"`
+	MatchSize   string `json:"match_size,omitempty"`
+	MatchWords  string `json:"match_words,omitempty"`
+	MatchLines  string `json:"match_lines,omitempty"`
+	MatchRegex  string `json:"match_regex,omitempty"`
+	MatchHeader string `json:"match_header,omitempty"`
+	MatchTime   string `json:"match_time,omitempty"`
+	MatchMode   string `json:"match_mode,omitempty"`
+
+	FilterStatus string `json:"filter_status,omitempty"`
+	FilterSize   string `json:"filter_size,omitempty"`
+	FilterWords  string `json:"filter_words,omitempty"`
+	FilterLines  string `json:"filter_lines,omitempty"`
+	FilterRegex  string `json:"filter_regex,omitempty"`
+	FilterHeader string `json:"filter_header,omitempty"`
+	FilterTime   string `json:"filter_time,omitempty"`
+	FilterMode   string `json:"filter_mode,omitempty"`
+}
+
+// RetryPolicy retry cfg for transport errors and flaky status codes
+type RetryPolicy struct {
+	MaxAttempts    int           `json:"max_attempts"`
+	RetryErrors    []string      `json:"retry_errors,omitempty"`
+	RetryStatus    []int         `json:"retry_status,omitempty"`
+	AttemptTimeout time.Duration `json:"attempt_timeout,omitempty"`
+	IdempotentOnly bool          `json:"idempotent_only"`
+}
+
+// Auth types
+const (
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+	AuthAPIKey = "apikey"
+	AuthHeader = "header"
+)
+
+// redacted replaces secrets in output
+const redacted = "[REDACTED]"
+
 // AuthConfig auth cfg
+// apikey sends APIKey in Header (X-API-Key by default), header sends Token in Header as is
 type AuthConfig struct {
 	Type     string `json:"type"`
 	Username string `json:"username,omitempty"`
@@ -77,6 +211,90 @@ type AuthConfig struct {
 	Header   string `json:"header,omitempty"`
 }
 
+// Redacted returns copy of cfg without secrets
+func (a AuthConfig) Redacted() AuthConfig {
+	if a.Password != "" {
+		a.Password = redacted
+	}
+	if a.Token != "" {
+		a.Token = redacted
+	}
+	if a.APIKey != "" {
+		a.APIKey = redacted
+	}
+	return a
+}
+
+// MarshalJSON marshals cfg with secrets redacted
+func (a AuthConfig) MarshalJSON() ([]byte, error) {
+	type plain AuthConfig
+	return json.Marshal(plain(a.Redacted()))
+}
+
+// String formats cfg for logs with secrets redacted
+func (a AuthConfig) String() string {
+	r := a.Redacted()
+	return fmt.Sprintf("{Type:%s Username:%s Password:%s Token:%s APIKey:%s Header:%s}",
+		r.Type, r.Username, r.Password, r.Token, r.APIKey, r.Header)
+}
+
+// GoString formats cfg for %#v with secrets redacted
+func (a AuthConfig) GoString() string {
+	return "types.AuthConfig" + a.String()
+}
+
+// LoginConfig login step executed before scan and repeated when session expires
+// Body may contain {{username}} and {{password}} placeholders
+type LoginConfig struct {
+	URL         string            `json:"url"`
+	Method      string            `json:"method"`
+	Body        string            `json:"body,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Password    string            `json:"password,omitempty"`
+
+	// token extraction, first non-empty wins: JSON path (data.token), regex group, response header
+	TokenPath           string `json:"token_path,omitempty"`
+	TokenRegex          string `json:"token_regex,omitempty"`
+	TokenResponseHeader string `json:"token_response_header,omitempty"`
+	// token is sent in TokenHeader (Authorization by default) with TokenPrefix (Bearer by default)
+	TokenHeader string `json:"token_header,omitempty"`
+	TokenPrefix string `json:"token_prefix,omitempty"`
+	// cookies login must set, empty accepts any
+	CookieNames []string `json:"cookie_names,omitempty"`
+
+	// session expiry detection, none set means session is never renewed,
+	// 401 of protected paths is not a reliable sign of expiry while bruteforcing
+	ExpiredStatus   []int  `json:"expired_status,omitempty"`
+	ExpiredRedirect string `json:"expired_redirect,omitempty"`
+	ExpiredMarker   string `json:"expired_marker,omitempty"`
+}
+
+// MarshalJSON marshals cfg with password redacted
+func (l LoginConfig) MarshalJSON() ([]byte, error) {
+	type plain LoginConfig
+	if l.Password != "" {
+		l.Password = redacted
+	}
+	return json.Marshal(plain(l))
+}
+
+// RequestTemplate request with fuzz keywords in URL, header and cookie values or body
+type RequestTemplate struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Cookies map[string]string `json:"cookies,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	// Defaults values of keywords not fuzzed at the moment in sniper mode
+	Defaults map[string]string `json:"defaults,omitempty"`
+	// ServerName TLS SNI sent instead of URL host
+	ServerName string `json:"server_name,omitempty"`
+	// InsecureServerName skips certificate verification of ServerName connections, vhost scans set it
+	InsecureServerName bool `json:"insecure_server_name,omitempty"`
+}
+
 // BruteResult bruteforcer result
 type BruteResult struct {
 	URL        string            `json:"url"`
@@ -88,4 +306,79 @@ type BruteResult struct {
 	Title      string            `json:"title,omitempty"`
 	Timestamp  time.Time         `json:"timestamp"`
 	Error      string            `json:"error,omitempty"`
+	Attempts   int               `json:"attempts,omitempty"`
+	Wildcard   bool              `json:"wildcard,omitempty"`
+	Depth      int               `json:"depth,omitempty"`
+	Word       string            `json:"word,omitempty"`
+	Variant    string            `json:"variant,omitempty"`
+	Payload    map[string]string `json:"payload,omitempty"`
+	Methods    []string          `json:"methods,omitempty"`
+	Words      int               `json:"words,omitempty"`
+	Lines      int               `json:"lines,omitempty"`
+	Duration   time.Duration     `json:"duration,omitempty"`
+
+	// Finding type of non-path result, method override tells EffectiveMethod tunneled through wire Method by Override
+	Finding         string `json:"finding,omitempty"`
+	EffectiveMethod string `json:"effective_method,omitempty"`
+	Override        string `json:"override,omitempty"`
+	// Bypass names variant of blocked Parent that worked, Request is its raw request
+	Bypass  string `json:"bypass,omitempty"`
+	Parent  string `json:"parent,omitempty"`
+	Request string `json:"request,omitempty"`
+	// Host virtual host name the request was sent to
+	Host string `json:"host,omitempty"`
+	// Artifact kind of sensitive artifact derived from Parent
+	Artifact string `json:"artifact,omitempty"`
+	// Files paths tracked by exposed repository
+	Files []string `json:"files,omitempty"`
+}
+
+// Finding types of results that are not plain path hits
+const (
+	FindingMethodOverride = "method-override"
+	FindingAccessBypass   = "access-bypass"
+	FindingVhost          = "vhost"
+	FindingArtifact       = "sensitive-artifact"
+)
+
+// ScopeConfig URLs crawler and bruteforcer may request, base URL host is always allowed
+type ScopeConfig struct {
+	// Include and Exclude regexes are matched against full URL, empty Include allows all
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	// Hosts allowed besides base host, "*.example.com" matches any subdomain
+	Hosts []string `json:"hosts,omitempty"`
+	// Schemes empty allows http and https, Ports empty allows base port, default ports if base uses one
+	// and default ports of other hosts
+	Schemes []string `json:"schemes,omitempty"`
+	Ports   []int    `json:"ports,omitempty"`
+	// PathPrefixes limit requested paths, empty allows all
+	PathPrefixes []string `json:"path_prefixes,omitempty"`
+	// Never regexes of URLs never requested, nil keeps logout patterns of scope.DefaultNever
+	Never []string `json:"never,omitempty"`
+}
+
+// OutOfScope scope metadata value of endpoints found but not requested
+const OutOfScope = "out-of-scope"
+
+// Parameter locations
+const (
+	ParamQuery = "query"
+	ParamForm  = "form"
+	ParamJSON  = "json"
+	// locations declared by API specs
+	ParamPath   = "path"
+	ParamHeader = "header"
+	ParamCookie = "cookie"
+)
+
+// Parameter endpoint parameter, Reasons tell how it changed response: status, content, reflected
+type Parameter struct {
+	Name     string   `json:"name"`
+	Location string   `json:"location"`
+	Reasons  []string `json:"reasons,omitempty"`
+
+	// Required and Type come from API spec
+	Required bool   `json:"required,omitempty"`
+	Type     string `json:"type,omitempty"`
 }
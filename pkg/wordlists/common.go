@@ -97,3 +97,20 @@ func (c *Common) GetAuth() []string {
 		"authenticate", "password", "reset",
 	}
 }
+
+// GetParams returns common query and body parameter names
+func (c *Common) GetParams() []string {
+	return []string{
+		"id", "uid", "user", "user_id", "username", "email", "name",
+		"q", "query", "search", "s", "keyword", "filter", "sort", "order",
+		"page", "limit", "offset", "count", "size", "per_page", "start", "end",
+		"debug", "test", "admin", "verbose", "dev", "preview", "draft",
+		"token", "access_token", "api_key", "apikey", "key", "secret", "auth",
+		"callback", "jsonp", "redirect", "redirect_uri", "return", "return_url", "next", "url", "continue",
+		"file", "filename", "path", "dir", "folder", "template", "include", "load",
+		"action", "cmd", "command", "exec", "method", "mode", "type", "format", "view",
+		"lang", "locale", "version", "v", "fields", "expand", "embed", "include_deleted",
+		"role", "group", "is_admin", "permissions", "status", "state", "category",
+		"from", "to", "date", "since", "until", "ref", "source", "target", "data",
+	}
+}